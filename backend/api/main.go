@@ -1,11 +1,21 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"log"
+	"net"
+	nethttp "net/http"
 	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
 
 	"github.com/i-am-ashwin/spydr-crawler/backend/db"
 	"github.com/i-am-ashwin/spydr-crawler/backend/http"
+	"github.com/i-am-ashwin/spydr-crawler/backend/models"
+	"github.com/i-am-ashwin/spydr-crawler/backend/scheduler"
 	"github.com/i-am-ashwin/spydr-crawler/backend/worker"
 )
 
@@ -15,16 +25,76 @@ func main() {
 	db.AutoMigrate(dataBase)
 
 	// Start worker pool
-	pool := worker.CrawlerWorkerPool(dataBase)
+	pool := worker.CrawlerWorkerPool(dataBase, worker.Config{
+		Workers:           getEnvInt("WORKER_COUNT", 3),
+		PollInterval:      getEnvDuration("WORKER_POLL_INTERVAL", 2*time.Second),
+		JobTimeout:        getEnvDuration("JOB_TIMEOUT", 10*time.Minute),
+		LeaseDuration:     getEnvDuration("JOB_LEASE_DURATION", 2*time.Minute),
+		HeartbeatInterval: getEnvDuration("JOB_HEARTBEAT_INTERVAL", 30*time.Second),
+		MaxAttempts:       getEnvInt("JOB_MAX_ATTEMPTS", 3),
+		RetryBaseDelay:    getEnvDuration("JOB_RETRY_BASE_DELAY", 30*time.Second),
+		RetryMaxDelay:     getEnvDuration("JOB_RETRY_MAX_DELAY", 30*time.Minute),
+		BrowserTabs:       getEnvInt("BROWSER_MAX_TABS", 3),
+		PerformanceBudget: models.PerformanceBudget{
+			MaxTTFBMs:        ptr(getEnvFloat("PERF_MAX_TTFB_MS", 0)),
+			MaxLoadMs:        ptr(getEnvFloat("PERF_MAX_LOAD_MS", 0)),
+			MaxLCPMs:         ptr(getEnvFloat("PERF_MAX_LCP_MS", 0)),
+			MaxCLS:           ptr(getEnvFloat("PERF_MAX_CLS", 0)),
+			MaxTBTMs:         ptr(getEnvFloat("PERF_MAX_TBT_MS", 0)),
+			MaxTransferBytes: ptr(int64(getEnvInt("PERF_MAX_TRANSFER_BYTES", 0))),
+			MaxRequests:      ptr(getEnvInt("PERF_MAX_REQUESTS", 0)),
+		},
+	})
 	pool.Start()
 
+	schedules := scheduler.NewScheduler(dataBase, scheduler.Config{
+		PollInterval:   getEnvDuration("SCHEDULER_POLL_INTERVAL", 30*time.Second),
+		MissedRunGrace: getEnvDuration("SCHEDULER_MISSED_RUN_GRACE", time.Minute),
+	})
+	schedules.Start()
+
 	// Setup HTTP router with worker pool
 	router := http.SetupRouter(dataBase, pool)
-
-	log.Printf("Server starting on port %s", port)
-	if err := router.Run(":" + port); err != nil {
-		log.Fatal("Failed to start server:", err)
+	baseCtx, cancelBase := context.WithCancel(context.Background())
+	server := &nethttp.Server{
+		Addr:        ":" + port,
+		Handler:     router,
+		BaseContext: func(net.Listener) context.Context { return baseCtx },
 	}
+	// Long-lived SSE streams only end when their request context is done.
+	server.RegisterOnShutdown(cancelBase)
+
+	go func() {
+		log.Printf("Server starting on port %s", port)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, nethttp.ErrServerClosed) {
+			log.Fatal("Failed to start server:", err)
+		}
+	}()
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	<-ctx.Done()
+
+	log.Println("Shutting down")
+	shutdownTimeout := getEnvDuration("SHUTDOWN_TIMEOUT", 30*time.Second)
+
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancelShutdown()
+
+	serverDone := make(chan struct{})
+	go func() {
+		defer close(serverDone)
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			log.Printf("Error shutting down server: %v", err)
+			server.Close()
+		}
+	}()
+
+	schedules.Stop(shutdownCtx)
+	pool.Stop(shutdownCtx)
+	<-serverDone
+
+	log.Println("Shutdown completed")
 }
 
 func getEnv(k, def string) string {
@@ -33,3 +103,46 @@ func getEnv(k, def string) string {
 	}
 	return def
 }
+
+func getEnvInt(k string, def int) int {
+	v := os.Getenv(k)
+	if v == "" {
+		return def
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		log.Printf("Invalid %s %q, using %d", k, v, def)
+		return def
+	}
+	return n
+}
+
+func getEnvFloat(k string, def float64) float64 {
+	v := os.Getenv(k)
+	if v == "" {
+		return def
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		log.Printf("Invalid %s %q, using %v", k, v, def)
+		return def
+	}
+	return f
+}
+
+func ptr[T any](value T) *T {
+	return &value
+}
+
+func getEnvDuration(k string, def time.Duration) time.Duration {
+	v := os.Getenv(k)
+	if v == "" {
+		return def
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		log.Printf("Invalid %s %q, using %s", k, v, def)
+		return def
+	}
+	return d
+}
@@ -0,0 +1,272 @@
+package crawler
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// contrastScript finds visible text whose contrast with its background is
+// below WCAG AA: 4.5:1, or 3:1 for large text. Text over background images
+// is skipped because its background cannot be computed.
+const contrastScript = `(() => {
+	const parse = value => {
+		const match = /rgba?\(([^)]+)\)/.exec(value || '');
+		if (!match) return null;
+		const parts = match[1].split(/[\s,\/]+/).filter(Boolean).map(parseFloat);
+		return { r: parts[0], g: parts[1], b: parts[2], a: parts.length > 3 ? parts[3] : 1 };
+	};
+	const blend = (top, bottom) => ({
+		r: top.r * top.a + bottom.r * (1 - top.a),
+		g: top.g * top.a + bottom.g * (1 - top.a),
+		b: top.b * top.a + bottom.b * (1 - top.a),
+		a: 1,
+	});
+	const luminance = c => {
+		const channel = v => { v /= 255; return v <= 0.03928 ? v / 12.92 : Math.pow((v + 0.055) / 1.055, 2.4); };
+		return 0.2126 * channel(c.r) + 0.7152 * channel(c.g) + 0.0722 * channel(c.b);
+	};
+	const background = element => {
+		const layers = [];
+		for (let node = element; node; node = node.parentElement) {
+			const style = getComputedStyle(node);
+			if (style.backgroundImage && style.backgroundImage !== 'none') return null;
+			const color = parse(style.backgroundColor);
+			if (color && color.a > 0) {
+				layers.push(color);
+				if (color.a >= 1) break;
+			}
+		}
+		let result = { r: 255, g: 255, b: 255, a: 1 };
+		for (let i = layers.length - 1; i >= 0; i--) result = blend(layers[i], result);
+		return result;
+	};
+	const path = element => {
+		const parts = [];
+		for (let node = element; node && node.nodeType === 1; node = node.parentElement) {
+			if (node.id && document.querySelectorAll('#' + CSS.escape(node.id)).length === 1) {
+				parts.unshift('#' + CSS.escape(node.id));
+				break;
+			}
+			let part = node.localName;
+			const siblings = node.parentElement ? Array.from(node.parentElement.children).filter(child => child.localName === node.localName) : [];
+			if (siblings.length > 1) part += ':nth-of-type(' + (siblings.indexOf(node) + 1) + ')';
+			parts.unshift(part);
+		}
+		return parts.join(' > ');
+	};
+	const rgb = c => 'rgb(' + Math.round(c.r) + ', ' + Math.round(c.g) + ', ' + Math.round(c.b) + ')';
+
+	const issues = [];
+	const seen = new Set();
+	const walker = document.createTreeWalker(document.body || document.documentElement, NodeFilter.SHOW_TEXT);
+	while (walker.nextNode() && issues.length < 50) {
+		const element = walker.currentNode.parentElement;
+		if (!element || seen.has(element) || !walker.currentNode.textContent.trim()) continue;
+		seen.add(element);
+		if (element.closest('script, style, noscript, template')) continue;
+
+		const style = getComputedStyle(element);
+		const rect = element.getBoundingClientRect();
+		if (style.visibility === 'hidden' || parseFloat(style.opacity) === 0 || rect.width === 0 || rect.height === 0) continue;
+
+		const foreground = parse(style.color);
+		const back = background(element);
+		if (!foreground || !back) continue;
+
+		const text = luminance(blend(foreground, back));
+		const behind = luminance(back);
+		const ratio = (Math.max(text, behind) + 0.05) / (Math.min(text, behind) + 0.05);
+		const size = parseFloat(style.fontSize);
+		const required = size >= 24 || (parseInt(style.fontWeight, 10) >= 700 && size >= 18.66) ? 3 : 4.5;
+		if (ratio < required) {
+			issues.push({ selector: path(element), ratio: Math.round(ratio * 100) / 100, required: required, color: rgb(foreground), background: rgb(back) });
+		}
+	}
+	return issues;
+})()`
+
+type contrastIssue struct {
+	Selector   string  `json:"selector"`
+	Ratio      float64 `json:"ratio"`
+	Required   float64 `json:"required"`
+	Color      string  `json:"color"`
+	Background string  `json:"background"`
+}
+
+var unlabelledInputTypes = map[string]bool{
+	"hidden": true,
+	"submit": true,
+	"reset":  true,
+	"button": true,
+	"image":  true,
+}
+
+// auditAccessibility runs the checks that only need the DOM. Contrast needs
+// computed styles and comes from the browser.
+func auditAccessibility(node *html.Node) []Finding {
+	var findings []Finding
+	ids := make(map[string]int)
+	add := func(rule, severity string, n *html.Node, format string, args ...interface{}) {
+		findings = append(findings, Finding{
+			Rule:     rule,
+			Severity: severity,
+			Message:  fmt.Sprintf(format, args...),
+			Selector: cssPath(n, ids),
+		})
+	}
+
+	labelled := make(map[string]bool)
+	walkInDocumentOrder(node, func(n *html.Node) {
+		if id := attribute(n, "id"); id != "" {
+			ids[id]++
+		}
+		if strings.ToLower(n.Data) == "label" {
+			if target := attribute(n, "for"); target != "" {
+				labelled[target] = true
+			}
+		}
+	})
+
+	reported := make(map[string]bool)
+	previousLevel := 0
+	walkInDocumentOrder(node, func(n *html.Node) {
+		tag := strings.ToLower(n.Data)
+
+		if id := attribute(n, "id"); id != "" && ids[id] > 1 && !reported[id] {
+			reported[id] = true
+			add("duplicate_id", SeverityWarning, n, "id %q is used by %d elements", id, ids[id])
+		}
+
+		switch tag {
+		case "html":
+			if strings.TrimSpace(attribute(n, "lang")) == "" {
+				add("lang_missing", SeverityError, n, "The html element has no lang attribute")
+			}
+		case "img":
+			if !hasAttribute(n, "alt") && !isPresentational(n) {
+				add("img_missing_alt", SeverityError, n, "Image %q has no alt text", truncate(attribute(n, "src"), 256))
+			}
+		case "input", "select", "textarea":
+			inputType := strings.ToLower(attribute(n, "type"))
+			if tag == "input" && inputType == "image" && strings.TrimSpace(attribute(n, "alt")) == "" && !hasAriaName(n) {
+				add("img_missing_alt", SeverityError, n, "Image button has no alt text")
+			}
+			if tag == "input" && unlabelledInputTypes[inputType] {
+				return
+			}
+			if !labelled[attribute(n, "id")] && !insideLabel(n) && !hasAriaName(n) && strings.TrimSpace(attribute(n, "title")) == "" {
+				add("input_missing_label", SeverityError, n, "Form %s has no label", tag)
+			}
+		case "a":
+			if hasAttribute(n, "href") && !hasAccessibleName(n) {
+				add("link_empty", SeverityError, n, "Link to %q has no text", truncate(attribute(n, "href"), 256))
+			}
+		case "button":
+			if !hasAccessibleName(n) {
+				add("button_empty", SeverityError, n, "Button has no text")
+			}
+		case "h1", "h2", "h3", "h4", "h5", "h6":
+			level := int(tag[1] - '0')
+			if previousLevel > 0 && level > previousLevel+1 {
+				add("heading_skipped", SeverityWarning, n, "Heading jumps from h%d to h%d", previousLevel, level)
+			}
+			previousLevel = level
+		}
+	})
+
+	return findings
+}
+
+func contrastFindings(issues []contrastIssue) []Finding {
+	findings := make([]Finding, 0, len(issues))
+	for _, issue := range issues {
+		findings = append(findings, Finding{
+			Rule:     "low_contrast",
+			Severity: SeverityError,
+			Message:  fmt.Sprintf("Text contrast %.2f:1 is below %.1f:1 (%s on %s)", issue.Ratio, issue.Required, issue.Color, issue.Background),
+			Selector: issue.Selector,
+		})
+	}
+	return findings
+}
+
+// walkInDocumentOrder visits element nodes depth first, in source order.
+func walkInDocumentOrder(node *html.Node, visit func(*html.Node)) {
+	if node.Type == html.ElementNode {
+		visit(node)
+	}
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		walkInDocumentOrder(child, visit)
+	}
+}
+
+func isPresentational(n *html.Node) bool {
+	role := strings.ToLower(attribute(n, "role"))
+	return role == "presentation" || role == "none" || attribute(n, "aria-hidden") == "true"
+}
+
+func hasAriaName(n *html.Node) bool {
+	return strings.TrimSpace(attribute(n, "aria-label")) != "" || strings.TrimSpace(attribute(n, "aria-labelledby")) != ""
+}
+
+func insideLabel(n *html.Node) bool {
+	for parent := n.Parent; parent != nil; parent = parent.Parent {
+		if parent.Type == html.ElementNode && strings.ToLower(parent.Data) == "label" {
+			return true
+		}
+	}
+	return false
+}
+
+func hasAccessibleName(n *html.Node) bool {
+	if hasAriaName(n) || strings.TrimSpace(attribute(n, "title")) != "" || nodeText(n) != "" {
+		return true
+	}
+
+	var named bool
+	walkInDocumentOrder(n, func(child *html.Node) {
+		if child == n || named {
+			return
+		}
+		if hasAriaName(child) || (strings.ToLower(child.Data) == "img" && strings.TrimSpace(attribute(child, "alt")) != "") {
+			named = true
+		}
+	})
+	return named
+}
+
+// cssPath builds a selector for n, anchored at the nearest ancestor with a
+// unique id.
+func cssPath(n *html.Node, ids map[string]int) string {
+	var parts []string
+	for current := n; current != nil && current.Type == html.ElementNode; current = current.Parent {
+		if id := attribute(current, "id"); ids[id] == 1 && !strings.ContainsAny(id, " \t\n\"'#.:[]>+~()") {
+			parts = append(parts, "#"+id)
+			break
+		}
+
+		tag := strings.ToLower(current.Data)
+		position, count := 0, 0
+		if current.Parent != nil {
+			for sibling := current.Parent.FirstChild; sibling != nil; sibling = sibling.NextSibling {
+				if sibling.Type == html.ElementNode && strings.ToLower(sibling.Data) == tag {
+					count++
+					if sibling == current {
+						position = count
+					}
+				}
+			}
+		}
+		if count > 1 {
+			tag = fmt.Sprintf("%s:nth-of-type(%d)", tag, position)
+		}
+		parts = append(parts, tag)
+	}
+
+	for i, j := 0, len(parts)-1; i < j; i, j = i+1, j-1 {
+		parts[i], parts[j] = parts[j], parts[i]
+	}
+	return strings.Join(parts, " > ")
+}
@@ -0,0 +1,209 @@
+package crawler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+
+	"golang.org/x/net/html"
+)
+
+const maxFindingsPerAnalyzer = 200
+
+// Analyzer is a check that runs on every crawled page. Register it from an
+// init function; jobs can then enable or disable it by name.
+type Analyzer interface {
+	Name() string
+	Analyze(ctx context.Context, page *PageInput, report *Report) error
+}
+
+// BrowserAnalyzer is an Analyzer that also inspects the live page. When a page
+// is loaded in Chrome, AnalyzeBrowser runs in its tab after the page has
+// settled and before Analyze; tab is a chromedp context for use with
+// chromedp.Run.
+type BrowserAnalyzer interface {
+	Analyzer
+	AnalyzeBrowser(tab context.Context, url string, report *Report) error
+}
+
+// Response is the page as fetched over HTTP, before any JavaScript ran.
+type Response struct {
+	StatusCode int
+	Header     http.Header
+	Body       string
+}
+
+// PageInput is what an analyzer sees of a page. DOM is the post-JavaScript
+// DOM when Rendered is set and the parsed Response body otherwise.
+type PageInput struct {
+	URL       string
+	Response  Response
+	DOM       *html.Node
+	Links     []Link
+	Rendered  bool
+	InBrowser bool
+
+	result  *Result
+	session *session
+}
+
+type Finding struct {
+	Analyzer string
+	Rule     string
+	Severity string
+	Message  string
+	Selector string
+}
+
+type Metric struct {
+	Analyzer string
+	Name     string
+	Value    float64
+}
+
+// Report collects the findings and metrics of one analyzer on one page.
+type Report struct {
+	analyzer  string
+	findings  []Finding
+	metrics   []Metric
+	truncated int
+}
+
+func newReport(analyzer string) *Report {
+	return &Report{analyzer: analyzer}
+}
+
+func (r *Report) AddFinding(finding Finding) {
+	if len(r.findings) >= maxFindingsPerAnalyzer {
+		r.truncated++
+		return
+	}
+	finding.Analyzer = r.analyzer
+	finding.Message = truncate(finding.Message, 2048)
+	finding.Selector = truncate(finding.Selector, 1024)
+	r.findings = append(r.findings, finding)
+}
+
+// SetMetric records a named value, replacing any earlier value of that name.
+func (r *Report) SetMetric(name string, value float64) {
+	for i := range r.metrics {
+		if r.metrics[i].Name == name {
+			r.metrics[i].Value = value
+			return
+		}
+	}
+	r.metrics = append(r.metrics, Metric{Analyzer: r.analyzer, Name: name, Value: value})
+}
+
+type registration struct {
+	analyzer Analyzer
+	enabled  bool
+}
+
+var (
+	registryMutex sync.RWMutex
+	registry      []registration
+)
+
+// Register adds an analyzer that runs for every job that does not disable it.
+// It panics if the name is empty or already registered.
+func Register(analyzer Analyzer) {
+	register(analyzer, true)
+}
+
+// RegisterOptional adds an analyzer that only runs for jobs that enable it.
+func RegisterOptional(analyzer Analyzer) {
+	register(analyzer, false)
+}
+
+func register(analyzer Analyzer, enabled bool) {
+	registryMutex.Lock()
+	defer registryMutex.Unlock()
+
+	name := analyzer.Name()
+	if name == "" {
+		panic("crawler: analyzer name is empty")
+	}
+	for _, existing := range registry {
+		if existing.analyzer.Name() == name {
+			panic(fmt.Sprintf("crawler: analyzer %q registered twice", name))
+		}
+	}
+	registry = append(registry, registration{analyzer: analyzer, enabled: enabled})
+}
+
+type AnalyzerInfo struct {
+	Name             string `json:"name"`
+	EnabledByDefault bool   `json:"enabledByDefault"`
+	Browser          bool   `json:"browser"`
+}
+
+// RegisteredAnalyzers lists every analyzer, sorted by name.
+func RegisteredAnalyzers() []AnalyzerInfo {
+	registryMutex.RLock()
+	defer registryMutex.RUnlock()
+
+	infos := make([]AnalyzerInfo, 0, len(registry))
+	for _, entry := range registry {
+		_, browser := entry.analyzer.(BrowserAnalyzer)
+		infos = append(infos, AnalyzerInfo{Name: entry.analyzer.Name(), EnabledByDefault: entry.enabled, Browser: browser})
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos
+}
+
+// enabledAnalyzers returns the analyzers to run in registration order, with
+// overrides switching analyzers on or off by name.
+func enabledAnalyzers(overrides map[string]bool) []Analyzer {
+	registryMutex.RLock()
+	defer registryMutex.RUnlock()
+
+	var analyzers []Analyzer
+	for _, entry := range registry {
+		enabled, ok := overrides[entry.analyzer.Name()]
+		if !ok {
+			enabled = entry.enabled
+		}
+		if enabled {
+			analyzers = append(analyzers, entry.analyzer)
+		}
+	}
+	return analyzers
+}
+
+func (s *session) browserAnalyzers() []BrowserAnalyzer {
+	var analyzers []BrowserAnalyzer
+	for _, analyzer := range s.analyzers {
+		if browserAnalyzer, ok := analyzer.(BrowserAnalyzer); ok {
+			analyzers = append(analyzers, browserAnalyzer)
+		}
+	}
+	return analyzers
+}
+
+// runAnalyzers runs every enabled analyzer on the page, continuing the
+// reports the browser pass started. A failing analyzer is recorded as a
+// finding and does not fail the page.
+func (s *session) runAnalyzers(ctx context.Context, page *PageInput, reports map[string]*Report) {
+	for _, analyzer := range s.analyzers {
+		report := reports[analyzer.Name()]
+		if report == nil {
+			report = newReport(analyzer.Name())
+		}
+		if err := analyzer.Analyze(ctx, page, report); err != nil && ctx.Err() == nil {
+			report.AddFinding(Finding{Rule: "analyzer_failed", Severity: SeverityError, Message: err.Error()})
+		}
+		if report.truncated > 0 {
+			report.findings = append(report.findings, Finding{
+				Analyzer: analyzer.Name(),
+				Rule:     "findings_truncated",
+				Severity: SeverityInfo,
+				Message:  fmt.Sprintf("%d more findings were not recorded; only the first %d are kept", report.truncated, maxFindingsPerAnalyzer),
+			})
+		}
+		page.result.Findings = append(page.result.Findings, report.findings...)
+		page.result.Metrics = append(page.result.Metrics, report.metrics...)
+	}
+}
@@ -0,0 +1,137 @@
+package crawler
+
+import (
+	"context"
+
+	"github.com/chromedp/chromedp"
+)
+
+const (
+	AnalyzerPageInfo       = "page-info"
+	AnalyzerLinks          = "links"
+	AnalyzerHTMLVersion    = "html-version"
+	AnalyzerSEO            = "seo"
+	AnalyzerStructuredData = "structured-data"
+	AnalyzerAccessibility  = "accessibility"
+	AnalyzerOutline        = "outline"
+)
+
+func init() {
+	Register(pageInfoAnalyzer{})
+	Register(linksAnalyzer{})
+	Register(htmlVersionAnalyzer{})
+	Register(seoAnalyzer{})
+	Register(structuredDataAnalyzer{})
+	Register(accessibilityAnalyzer{})
+	Register(outlineAnalyzer{})
+}
+
+type pageInfoAnalyzer struct{}
+
+func (pageInfoAnalyzer) Name() string { return AnalyzerPageInfo }
+
+func (pageInfoAnalyzer) Analyze(ctx context.Context, page *PageInput, report *Report) error {
+	info := extractPageInfo(page.DOM)
+	result := page.result
+	result.Title = info.Title
+	result.H1, result.H2, result.H3 = info.H1, info.H2, info.H3
+	result.H4, result.H5, result.H6 = info.H4, info.H5, info.H6
+	result.HasLoginForm = info.HasLoginForm
+	return nil
+}
+
+type linksAnalyzer struct{}
+
+func (linksAnalyzer) Name() string { return AnalyzerLinks }
+
+func (linksAnalyzer) Analyze(ctx context.Context, page *PageInput, report *Report) error {
+	result := page.result
+	page.session.analyzeLinkMetrics(ctx, result, page.Links, page.URL)
+	report.SetMetric("internal_links", float64(result.InternalLinks))
+	report.SetMetric("external_links", float64(result.ExternalLinks))
+	report.SetMetric("broken_links", float64(result.BrokenLinks))
+	return nil
+}
+
+type htmlVersionAnalyzer struct{}
+
+func (htmlVersionAnalyzer) Name() string { return AnalyzerHTMLVersion }
+
+func (htmlVersionAnalyzer) Analyze(ctx context.Context, page *PageInput, report *Report) error {
+	page.result.HTMLVersion = detectHTMLVersion(page.Response.Body)
+	return nil
+}
+
+type seoAnalyzer struct{}
+
+func (seoAnalyzer) Name() string { return AnalyzerSEO }
+
+func (seoAnalyzer) Analyze(ctx context.Context, page *PageInput, report *Report) error {
+	meta := extractSEO(page.DOM, page.URL, page.Response.Header)
+	page.result.SEO = &meta
+	for _, finding := range checkSEO(documentTitle(page.DOM), meta, page.URL) {
+		report.AddFinding(finding)
+	}
+	return nil
+}
+
+type structuredDataAnalyzer struct{}
+
+func (structuredDataAnalyzer) Name() string { return AnalyzerStructuredData }
+
+func (structuredDataAnalyzer) Analyze(ctx context.Context, page *PageInput, report *Report) error {
+	items := extractStructuredData(page.DOM, page.URL)
+	page.result.StructuredData = items
+
+	invalid := 0
+	for _, item := range items {
+		if len(item.Errors) > 0 {
+			invalid++
+		}
+	}
+	report.SetMetric("items", float64(len(items)))
+	report.SetMetric("invalid_items", float64(invalid))
+	return nil
+}
+
+type accessibilityAnalyzer struct{}
+
+func (accessibilityAnalyzer) Name() string { return AnalyzerAccessibility }
+
+func (accessibilityAnalyzer) AnalyzeBrowser(tab context.Context, url string, report *Report) error {
+	var issues []contrastIssue
+	if err := chromedp.Run(tab, chromedp.Evaluate(contrastScript, &issues)); err != nil {
+		return err
+	}
+	for _, finding := range contrastFindings(issues) {
+		report.AddFinding(finding)
+	}
+	return nil
+}
+
+func (accessibilityAnalyzer) Analyze(ctx context.Context, page *PageInput, report *Report) error {
+	for _, finding := range auditAccessibility(page.DOM) {
+		report.AddFinding(finding)
+	}
+	return nil
+}
+
+type outlineAnalyzer struct{}
+
+func (outlineAnalyzer) Name() string { return AnalyzerOutline }
+
+func (outlineAnalyzer) Analyze(ctx context.Context, page *PageInput, report *Report) error {
+	outline := extractOutline(page.DOM)
+	page.result.Outline = &outline
+	report.SetMetric("headings", float64(countHeadings(outline.Headings)))
+	report.SetMetric("outline_issues", float64(len(outline.Issues)))
+	return nil
+}
+
+func countHeadings(headings []*Heading) int {
+	count := len(headings)
+	for _, heading := range headings {
+		count += countHeadings(heading.Children)
+	}
+	return count
+}
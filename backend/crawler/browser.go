@@ -0,0 +1,119 @@
+package crawler
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+
+	"github.com/chromedp/chromedp"
+)
+
+const defaultBrowserTabs = 3
+
+var ErrBrowserClosed = errors.New("browser pool is closed")
+
+var (
+	defaultBrowserPool     *BrowserPool
+	defaultBrowserPoolOnce sync.Once
+)
+
+// BrowserPool shares one headless Chrome between crawls. Each capture borrows
+// a tab, at most maxTabs at a time, and the browser is relaunched on the next
+// borrow if it has crashed.
+type BrowserPool struct {
+	tabs chan struct{}
+
+	mutex      sync.Mutex
+	browserCtx context.Context
+	cancel     context.CancelFunc
+	closed     bool
+}
+
+func NewBrowserPool(maxTabs int) *BrowserPool {
+	if maxTabs <= 0 {
+		maxTabs = defaultBrowserTabs
+	}
+	return &BrowserPool{tabs: make(chan struct{}, maxTabs)}
+}
+
+func sharedBrowserPool() *BrowserPool {
+	defaultBrowserPoolOnce.Do(func() {
+		defaultBrowserPool = NewBrowserPool(defaultBrowserTabs)
+	})
+	return defaultBrowserPool
+}
+
+// Tab borrows a tab, waiting while all tabs are in use. The tab is closed
+// when ctx is done or release is called, whichever comes first.
+func (p *BrowserPool) Tab(ctx context.Context) (context.Context, func(), error) {
+	select {
+	case p.tabs <- struct{}{}:
+	case <-ctx.Done():
+		return nil, nil, ctx.Err()
+	}
+
+	browserCtx, err := p.browser()
+	if err != nil {
+		<-p.tabs
+		return nil, nil, err
+	}
+
+	tabCtx, cancelTab := chromedp.NewContext(browserCtx)
+	stop := context.AfterFunc(ctx, cancelTab)
+
+	var once sync.Once
+	release := func() {
+		once.Do(func() {
+			stop()
+			cancelTab()
+			<-p.tabs
+		})
+	}
+	return tabCtx, release, nil
+}
+
+func (p *BrowserPool) browser() (context.Context, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.closed {
+		return nil, ErrBrowserClosed
+	}
+	if p.browserCtx != nil {
+		if p.browserCtx.Err() == nil {
+			return p.browserCtx, nil
+		}
+		log.Printf("Browser exited unexpectedly, relaunching")
+		p.cancel()
+	}
+
+	allocCtx, cancelAlloc := chromedp.NewExecAllocator(context.Background(), chromedp.DefaultExecAllocatorOptions[:]...)
+	browserCtx, cancelBrowser := chromedp.NewContext(allocCtx)
+	cancel := func() {
+		cancelBrowser()
+		cancelAlloc()
+	}
+
+	// Running with no actions starts the browser.
+	if err := chromedp.Run(browserCtx); err != nil {
+		cancel()
+		p.browserCtx, p.cancel = nil, nil
+		return nil, err
+	}
+
+	p.browserCtx, p.cancel = browserCtx, cancel
+	return browserCtx, nil
+}
+
+// Close shuts the browser down. Tabs still in use are closed with it.
+func (p *BrowserPool) Close() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.closed = true
+	if p.cancel != nil {
+		p.cancel()
+		p.browserCtx, p.cancel = nil, nil
+	}
+}
@@ -0,0 +1,152 @@
+package crawler
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/cdproto/runtime"
+)
+
+const (
+	IssueConsoleError  = "console_error"
+	IssueException     = "exception"
+	IssueHTTPError     = "http_error"
+	IssueRequestFailed = "request_failed"
+
+	maxIssuesPerPage = 200
+)
+
+// BrowserIssue is a JavaScript error or failed sub-resource request seen
+// while the page was loaded in the browser.
+type BrowserIssue struct {
+	Kind         string
+	Message      string
+	URL          string
+	StatusCode   int
+	ResourceType string
+	Line         int
+	Column       int
+}
+
+type issueCollector struct {
+	mutex    sync.Mutex
+	issues   []BrowserIssue
+	requests map[network.RequestID]*network.Request
+}
+
+func newIssueCollector() *issueCollector {
+	return &issueCollector{
+		requests: make(map[network.RequestID]*network.Request),
+	}
+}
+
+func (c *issueCollector) add(issue BrowserIssue) {
+	if len(c.issues) < maxIssuesPerPage {
+		c.issues = append(c.issues, issue)
+	}
+}
+
+func (c *issueCollector) listen(ev interface{}) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	switch event := ev.(type) {
+	case *runtime.EventConsoleAPICalled:
+		if event.Type != runtime.APITypeError && event.Type != runtime.APITypeAssert {
+			return
+		}
+		issue := BrowserIssue{Kind: IssueConsoleError, Message: consoleMessage(event.Args)}
+		if event.StackTrace != nil && len(event.StackTrace.CallFrames) > 0 {
+			frame := event.StackTrace.CallFrames[0]
+			issue.URL = frame.URL
+			issue.Line = int(frame.LineNumber) + 1
+			issue.Column = int(frame.ColumnNumber) + 1
+		}
+		c.add(issue)
+
+	case *runtime.EventExceptionThrown:
+		details := event.ExceptionDetails
+		if details == nil {
+			return
+		}
+		message := details.Text
+		if details.Exception != nil && details.Exception.Description != "" {
+			message = details.Exception.Description
+		}
+		c.add(BrowserIssue{
+			Kind:    IssueException,
+			Message: truncate(message, 2048),
+			URL:     details.URL,
+			Line:    int(details.LineNumber) + 1,
+			Column:  int(details.ColumnNumber) + 1,
+		})
+
+	case *network.EventRequestWillBeSent:
+		c.requests[event.RequestID] = event.Request
+
+	case *network.EventResponseReceived:
+		if event.Response == nil || event.Response.Status < 400 {
+			return
+		}
+		c.add(BrowserIssue{
+			Kind:         IssueHTTPError,
+			Message:      fmt.Sprintf("%d %s", event.Response.Status, event.Response.StatusText),
+			URL:          event.Response.URL,
+			StatusCode:   int(event.Response.Status),
+			ResourceType: event.Type.String(),
+		})
+
+	case *network.EventLoadingFailed:
+		if event.Canceled && event.BlockedReason == "" {
+			return
+		}
+		message := event.ErrorText
+		if event.BlockedReason != "" {
+			message = "blocked: " + event.BlockedReason.String()
+		}
+		issue := BrowserIssue{
+			Kind:         IssueRequestFailed,
+			Message:      message,
+			ResourceType: event.Type.String(),
+		}
+		if request := c.requests[event.RequestID]; request != nil {
+			issue.URL = request.URL
+		}
+		c.add(issue)
+	}
+}
+
+func (c *issueCollector) result() []BrowserIssue {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return append([]BrowserIssue(nil), c.issues...)
+}
+
+func consoleMessage(args []*runtime.RemoteObject) string {
+	parts := make([]string, 0, len(args))
+	for _, arg := range args {
+		switch {
+		case arg.Description != "":
+			parts = append(parts, arg.Description)
+		case len(arg.Value) > 0:
+			parts = append(parts, strings.Trim(string(arg.Value), `"`))
+		default:
+			parts = append(parts, arg.Type.String())
+		}
+	}
+	return truncate(strings.Join(parts, " "), 2048)
+}
+
+func countIssues(issues []BrowserIssue) (consoleErrors, failedRequests int) {
+	for _, issue := range issues {
+		switch issue.Kind {
+		case IssueConsoleError, IssueException:
+			consoleErrors++
+		case IssueHTTPError, IssueRequestFailed:
+			failedRequests++
+		}
+	}
+	return consoleErrors, failedRequests
+}
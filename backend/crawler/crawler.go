@@ -1,17 +1,91 @@
 package crawler
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"io"
+	"mime"
 	"net/http"
 	"net/url"
 	"regexp"
 	"strings"
+	"sync"
 	"time"
 
 	"golang.org/x/net/html"
 )
 
+const (
+	userAgent       = "spydr-crawler/1.0"
+	defaultTimeout  = 10 * time.Minute
+	pageMaxBodySize = 10 * 1024 * 1024
+)
+
+// ErrPageTooLarge is returned for pages whose HTML is over pageMaxBodySize,
+// rather than analyzing a truncated document.
+var ErrPageTooLarge = errors.New("page is larger than 10MB")
+
+type Options struct {
+	MaxDepth     int
+	MaxPages     int
+	IgnoreRobots bool
+	Timeout      time.Duration
+	Screenshot   ScreenshotOptions
+	Browser      *BrowserPool
+	RenderMode   string
+	RecordHAR    bool
+	// PDF prints the root page to a PDF when set.
+	PDF *PDFOptions
+	// Analyzers switches registered analyzers on or off by name.
+	Analyzers map[string]bool
+}
+
+type session struct {
+	options         Options
+	pageClient      *http.Client
+	linkClient      *http.Client
+	robots          *robotsCache
+	limiter         *hostLimiter
+	linkChecks      map[string]*linkCheck
+	linkChecksMutex sync.Mutex
+	analyzers       []Analyzer
+}
+
+func newSession(options Options) *session {
+	s := &session{
+		options:    options,
+		pageClient: &http.Client{Timeout: 15 * time.Second},
+		linkClient: &http.Client{
+			Timeout: 10 * time.Second,
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		limiter:    defaultHostLimiter,
+		linkChecks: make(map[string]*linkCheck),
+		analyzers:  enabledAnalyzers(options.Analyzers),
+	}
+	if !options.IgnoreRobots {
+		s.robots = defaultRobotsCache
+	}
+	return s
+}
+
+func (s *session) checkRobots(ctx context.Context, rawURL string) error {
+	if s.robots == nil {
+		return nil
+	}
+	allowed, err := s.robots.Allowed(ctx, rawURL)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return fmt.Errorf("%w: %s", ErrBlockedByRobots, rawURL)
+	}
+	return nil
+}
+
 type Result struct {
 	Title          string
 	H1             int
@@ -26,49 +100,113 @@ type Result struct {
 	HasLoginForm   bool
 	HTMLVersion    string
 	ScreenshotPath string
+	HARPath        string
+	PDFPath        string
+	Links          []LinkResult
+	Incomplete     bool
+	RenderMode     string
+	BrowserIssues  []BrowserIssue
+	ConsoleErrors  int
+	FailedRequests int
+	Performance    *PerformanceMetrics
+	SEO            *SEOMetadata
+	StructuredData []StructuredItem
+	Outline        *HeadingOutline
+	Findings       []Finding
+	Metrics        []Metric
 }
 
-func Crawl(targetURL string) (Result, error) {
-	htmlContent, err := fetchWebpage(targetURL)
-	if err != nil {
-		return Result{}, err
-	}
+type Link struct {
+	Href string
+	Text string
+	Rel  string
+}
 
-	node, err := parseHTML(htmlContent)
-	if err != nil {
-		return Result{}, err
-	}
-	screenshotPath, err := TakeScreenshot(targetURL)
-	if err != nil {
-		return Result{}, err
-	}
-	result := extractPageInfo(node)
-	result.ScreenshotPath = screenshotPath
-	links := extractLinks(node)
-	analyzeLinkMetrics(&result, links, targetURL)
-	result.HTMLVersion = detectHTMLVersion(htmlContent)
+const (
+	LinkStatusOK          = "ok"
+	LinkStatusRedirect    = "redirect"
+	LinkStatusClientError = "client_error"
+	LinkStatusServerError = "server_error"
+	LinkStatusTimeout     = "timeout"
+	LinkStatusDNSFailure  = "dns_failure"
+	LinkStatusTLSFailure  = "tls_failure"
+	LinkStatusError       = "error"
+	LinkStatusBlocked     = "blocked"
+	LinkStatusSkipped     = "skipped"
+)
 
-	return result, nil
+type RedirectHop struct {
+	URL        string
+	StatusCode int
 }
 
-func fetchWebpage(targetURL string) (string, error) {
-	client := &http.Client{Timeout: 15 * time.Second}
-	resp, err := client.Get(targetURL)
+type LinkResult struct {
+	Href          string
+	URL           string
+	Text          string
+	Rel           string
+	Internal      bool
+	StatusCode    int
+	StatusClass   string
+	RedirectChain []RedirectHop
+	Error         string
+}
+
+func Crawl(ctx context.Context, targetURL string, options Options) (Result, error) {
+	options.MaxDepth = 0
+	result, _, err := CrawlSite(ctx, targetURL, options)
+	return result, err
+}
+
+func (s *session) fetchWebpage(ctx context.Context, targetURL string) (Response, error) {
+	if err := s.checkRobots(ctx, targetURL); err != nil {
+		return Response{}, err
+	}
+	if err := s.throttle(ctx, targetURL); err != nil {
+		return Response{}, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, targetURL, nil)
 	if err != nil {
-		return "", err
+		return Response{}, err
+	}
+	req.Header.Set("User-Agent", userAgent)
+	resp, err := s.pageClient.Do(req)
+	if err != nil {
+		return Response{}, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode >= 400 {
-		return "", errors.New("fetch status " + resp.Status)
+		return Response{}, &StatusError{StatusCode: resp.StatusCode, Status: resp.Status}
 	}
 
-	bodyBytes, err := io.ReadAll(resp.Body)
+	if contentType := resp.Header.Get("Content-Type"); !isHTMLContentType(contentType) {
+		return Response{}, &NotHTMLError{ContentType: contentType}
+	}
+
+	bodyBytes, err := io.ReadAll(io.LimitReader(resp.Body, pageMaxBodySize+1))
 	if err != nil {
-		return "", err
+		return Response{}, err
+	}
+	if len(bodyBytes) > pageMaxBodySize {
+		return Response{}, ErrPageTooLarge
 	}
 
-	return string(bodyBytes), nil
+	return Response{StatusCode: resp.StatusCode, Header: resp.Header, Body: string(bodyBytes)}, nil
+}
+
+// isHTMLContentType accepts HTML and XHTML, and responses without a
+// Content-Type, which browsers sniff as HTML.
+func isHTMLContentType(contentType string) bool {
+	if contentType == "" {
+		return true
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType == "text/html" || mediaType == "application/xhtml+xml"
 }
 
 func parseHTML(htmlContent string) (*html.Node, error) {
@@ -85,10 +223,6 @@ func extractPageInfo(node *html.Node) Result {
 
 		tag := strings.ToLower(n.Data)
 		switch tag {
-		case "title":
-			if n.FirstChild != nil {
-				result.Title = strings.TrimSpace(n.FirstChild.Data)
-			}
 		case "form":
 			if hasPasswordInput(n) {
 				result.HasLoginForm = true
@@ -108,25 +242,76 @@ func extractPageInfo(node *html.Node) Result {
 		}
 	})
 
+	result.Title = documentTitle(node)
 	return result
 }
 
-func extractLinks(node *html.Node) []string {
-	var links []string
+// documentTitle returns the text of the first title element.
+func documentTitle(node *html.Node) string {
+	var title string
+	found := false
+	walkThroughHtmlNodes(node, func(n *html.Node) {
+		if found || n.Type != html.ElementNode || strings.ToLower(n.Data) != "title" {
+			return
+		}
+		found = true
+		if n.FirstChild != nil {
+			title = strings.TrimSpace(n.FirstChild.Data)
+		}
+	})
+	return title
+}
+
+func extractLinks(node *html.Node) []Link {
+	var links []Link
 
 	walkThroughHtmlNodes(node, func(n *html.Node) {
-		if n.Type == html.ElementNode && strings.ToLower(n.Data) == "a" {
-			for _, attr := range n.Attr {
-				if attr.Key == "href" && attr.Val != "" {
-					links = append(links, attr.Val)
-				}
+		if n.Type != html.ElementNode || strings.ToLower(n.Data) != "a" {
+			return
+		}
+
+		link := Link{Text: truncate(nodeText(n), 1024)}
+		for _, attr := range n.Attr {
+			switch attr.Key {
+			case "href":
+				link.Href = attr.Val
+			case "rel":
+				link.Rel = strings.Join(strings.Fields(attr.Val), " ")
 			}
 		}
+		if link.Href != "" {
+			links = append(links, link)
+		}
 	})
 
 	return links
 }
 
+func nodeText(node *html.Node) string {
+	var parts []string
+
+	var collect func(*html.Node)
+	collect = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			parts = append(parts, n.Data)
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			collect(child)
+		}
+	}
+	collect(node)
+
+	return strings.Join(strings.Fields(strings.Join(parts, " ")), " ")
+}
+
+func truncate(value string, maxLength int) string {
+	runes := []rune(value)
+	if len(runes) <= maxLength {
+		return value
+	}
+	return string(runes[:maxLength])
+}
+
 func walkThroughHtmlNodes(node *html.Node, runFunction func(*html.Node)) {
 	queue := []*html.Node{node}
 
@@ -140,29 +325,48 @@ func walkThroughHtmlNodes(node *html.Node, runFunction func(*html.Node)) {
 	}
 }
 
-func analyzeLinkMetrics(result *Result, links []string, baseURL string) {
+func (s *session) analyzeLinkMetrics(ctx context.Context, result *Result, links []Link, baseURL string) {
 	if len(links) == 0 {
 		return
 	}
 
 	baseHost := hostOf(baseURL)
-	client := &http.Client{Timeout: 10 * time.Second}
+	var absoluteLinks []string
 
 	for _, link := range links {
-		if isSkippableLink(link) {
+		if isSkippableLink(link.Href) {
 			continue
 		}
 
-		if isInternal(link, baseHost) {
+		internal := isInternal(link.Href, baseHost)
+		if internal {
 			result.InternalLinks++
 		} else {
 			result.ExternalLinks++
 		}
 
-		absoluteLink := absoluteURL(link, baseURL)
-		if isBrokenLink(client, absoluteLink) {
+		absoluteLink := absoluteURL(link.Href, baseURL)
+		absoluteLinks = append(absoluteLinks, absoluteLink)
+		result.Links = append(result.Links, LinkResult{
+			Href:     truncate(link.Href, 2048),
+			URL:      truncate(absoluteLink, 2048),
+			Text:     link.Text,
+			Rel:      link.Rel,
+			Internal: internal,
+		})
+	}
+
+	for i, status := range s.checkLinks(ctx, absoluteLinks) {
+		if IsBrokenClass(status.statusClass) {
 			result.BrokenLinks++
 		}
+		if status.statusClass == LinkStatusSkipped {
+			result.Incomplete = true
+		}
+		result.Links[i].StatusCode = status.statusCode
+		result.Links[i].StatusClass = status.statusClass
+		result.Links[i].RedirectChain = status.redirectChain
+		result.Links[i].Error = status.err
 	}
 }
 
@@ -175,11 +379,6 @@ func isSkippableLink(link string) bool {
 		strings.HasPrefix(link, "tel:")
 }
 
-func isBrokenLink(client *http.Client, url string) bool {
-	statusCode := headStatus(client, url)
-	return statusCode >= 400 && statusCode != 0
-}
-
 func hostOf(raw string) string {
 	parsedUrl, err := url.Parse(raw)
 	if err != nil {
@@ -211,17 +410,6 @@ func isInternal(link, baseHost string) bool {
 	return parsedUrl.Host == baseHost
 }
 
-func headStatus(client *http.Client, u string) int {
-	req, _ := http.NewRequest(http.MethodHead, u, nil)
-	req.Header.Set("User-Agent", "spydr-crawler/1.0")
-	resp, err := client.Do(req)
-	if err != nil {
-		return 0
-	}
-	resp.Body.Close()
-	return resp.StatusCode
-}
-
 func hasPasswordInput(form *html.Node) bool {
 	var found bool
 
@@ -0,0 +1,85 @@
+package crawler
+
+import (
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"syscall"
+)
+
+type StatusError struct {
+	StatusCode int
+	Status     string
+}
+
+func (e *StatusError) Error() string {
+	return "fetch status " + e.Status
+}
+
+// NotHTMLError is returned for pages whose Content-Type is not HTML, such as
+// PDFs or videos linked from a site.
+type NotHTMLError struct {
+	ContentType string
+}
+
+func (e *NotHTMLError) Error() string {
+	return "not an HTML page: " + e.ContentType
+}
+
+type transientError struct {
+	err error
+}
+
+func (e *transientError) Error() string {
+	return e.err.Error()
+}
+
+func (e *transientError) Unwrap() error {
+	return e.err
+}
+
+func markTransient(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &transientError{err: err}
+}
+
+// IsTransient reports whether a crawl error is likely to go away on retry,
+// such as timeouts, DNS hiccups, 5xx responses or a failed browser launch.
+func IsTransient(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var marked *transientError
+	if errors.As(err, &marked) {
+		return true
+	}
+
+	if errors.Is(err, ErrBlockedByRobots) || errors.Is(err, ErrDeadlineExceeded) {
+		return false
+	}
+
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode >= 500 ||
+			statusErr.StatusCode == http.StatusRequestTimeout ||
+			statusErr.StatusCode == http.StatusTooManyRequests
+	}
+
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return !dnsErr.IsNotFound
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	return errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, io.ErrUnexpectedEOF)
+}
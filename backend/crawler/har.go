@@ -0,0 +1,366 @@
+package crawler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/cdproto/page"
+)
+
+const harPageID = "page_1"
+
+type harLog struct {
+	Log harContent `json:"log"`
+}
+
+type harContent struct {
+	Version string     `json:"version"`
+	Creator harCreator `json:"creator"`
+	Pages   []harPage  `json:"pages"`
+	Entries []harEntry `json:"entries"`
+}
+
+type harCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type harPage struct {
+	StartedDateTime time.Time      `json:"startedDateTime"`
+	ID              string         `json:"id"`
+	Title           string         `json:"title"`
+	PageTimings     harPageTimings `json:"pageTimings"`
+}
+
+type harPageTimings struct {
+	OnContentLoad float64 `json:"onContentLoad"`
+	OnLoad        float64 `json:"onLoad"`
+}
+
+type harEntry struct {
+	Pageref         string      `json:"pageref"`
+	StartedDateTime time.Time   `json:"startedDateTime"`
+	Time            float64     `json:"time"`
+	Request         harRequest  `json:"request"`
+	Response        harResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         harTimings  `json:"timings"`
+	ServerIPAddress string      `json:"serverIPAddress,omitempty"`
+	ResourceType    string      `json:"_resourceType,omitempty"`
+	Error           string      `json:"_error,omitempty"`
+}
+
+type harRequest struct {
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []harNameValue `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	QueryString []harNameValue `json:"queryString"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type harResponse struct {
+	Status      int            `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []harNameValue `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	Content     harBody        `json:"content"`
+	RedirectURL string         `json:"redirectURL"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type harBody struct {
+	Size     int    `json:"size"`
+	MimeType string `json:"mimeType"`
+}
+
+type harNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type harTimings struct {
+	Blocked float64 `json:"blocked"`
+	DNS     float64 `json:"dns"`
+	Connect float64 `json:"connect"`
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+	SSL     float64 `json:"ssl"`
+}
+
+type harRecording struct {
+	entry       harEntry
+	requestTime time.Time
+	timing      *network.ResourceTiming
+	done        bool
+}
+
+// harRecorder turns the DevTools network events of one page load into a HAR 1.2 log.
+type harRecorder struct {
+	mutex         sync.Mutex
+	started       time.Time
+	startedWall   time.Time
+	contentLoaded time.Time
+	loaded        time.Time
+	order         []*harRecording
+	active        map[network.RequestID]*harRecording
+}
+
+func newHARRecorder() *harRecorder {
+	return &harRecorder{active: make(map[network.RequestID]*harRecording)}
+}
+
+func (r *harRecorder) listen(ev interface{}) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	switch event := ev.(type) {
+	case *network.EventRequestWillBeSent:
+		if event.Request == nil || event.Timestamp == nil {
+			return
+		}
+		if previous := r.active[event.RequestID]; previous != nil && event.RedirectResponse != nil {
+			previous.setResponse(event.RedirectResponse, "")
+			previous.finish(event.Timestamp.Time())
+		}
+
+		started := time.Now()
+		if event.WallTime != nil {
+			started = event.WallTime.Time()
+		}
+		if r.started.IsZero() {
+			r.started = event.Timestamp.Time()
+			r.startedWall = started
+		}
+
+		recording := &harRecording{
+			requestTime: event.Timestamp.Time(),
+			entry: harEntry{
+				Pageref:         harPageID,
+				StartedDateTime: started,
+				Request:         newHARRequest(event.Request),
+				Response:        harResponse{Cookies: []harNameValue{}, Headers: []harNameValue{}, HeadersSize: -1, BodySize: -1},
+				ResourceType:    strings.ToLower(event.Type.String()),
+			},
+		}
+		r.active[event.RequestID] = recording
+		r.order = append(r.order, recording)
+
+	case *network.EventResponseReceived:
+		if recording := r.active[event.RequestID]; recording != nil && event.Response != nil {
+			recording.setResponse(event.Response, strings.ToLower(event.Type.String()))
+		}
+
+	case *network.EventLoadingFinished:
+		if recording := r.active[event.RequestID]; recording != nil && event.Timestamp != nil {
+			recording.entry.Response.BodySize = int(event.EncodedDataLength)
+			recording.finish(event.Timestamp.Time())
+		}
+
+	case *network.EventLoadingFailed:
+		if recording := r.active[event.RequestID]; recording != nil && event.Timestamp != nil {
+			recording.entry.Error = event.ErrorText
+			recording.finish(event.Timestamp.Time())
+		}
+
+	case *page.EventDomContentEventFired:
+		if event.Timestamp != nil && r.contentLoaded.IsZero() {
+			r.contentLoaded = event.Timestamp.Time()
+		}
+
+	case *page.EventLoadEventFired:
+		if event.Timestamp != nil && r.loaded.IsZero() {
+			r.loaded = event.Timestamp.Time()
+		}
+	}
+}
+
+func (recording *harRecording) setResponse(response *network.Response, resourceType string) {
+	entry := &recording.entry
+	entry.Response.Status = int(response.Status)
+	entry.Response.StatusText = response.StatusText
+	entry.Response.HTTPVersion = httpVersion(response.Protocol)
+	entry.Response.Headers = harHeaders(response.Headers)
+	entry.Response.Content = harBody{Size: int(response.EncodedDataLength), MimeType: response.MimeType}
+	entry.Response.RedirectURL = headerValue(response.Headers, "Location")
+	entry.Response.BodySize = int(response.EncodedDataLength)
+	entry.Request.HTTPVersion = entry.Response.HTTPVersion
+	if len(response.RequestHeaders) > 0 {
+		entry.Request.Headers = harHeaders(response.RequestHeaders)
+	}
+	entry.ServerIPAddress = strings.Trim(response.RemoteIPAddress, "[]")
+	if resourceType != "" {
+		entry.ResourceType = resourceType
+	}
+	recording.timing = response.Timing
+}
+
+func (recording *harRecording) finish(finished time.Time) {
+	if recording.done {
+		return
+	}
+	recording.done = true
+	recording.entry.Time = milliseconds(finished.Sub(recording.requestTime))
+	recording.entry.Timings = harTimingsFrom(recording.timing, recording.entry.Time, finished)
+}
+
+func (r *harRecorder) marshal(title string) ([]byte, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	now := time.Now()
+	entries := make([]harEntry, 0, len(r.order))
+	for _, recording := range r.order {
+		if !recording.done {
+			recording.entry.Time = milliseconds(now.Sub(recording.entry.StartedDateTime))
+			recording.entry.Timings = harTimings{Blocked: -1, DNS: -1, Connect: -1, Send: 0, Wait: recording.entry.Time, Receive: 0, SSL: -1}
+		}
+		entries = append(entries, recording.entry)
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].StartedDateTime.Before(entries[j].StartedDateTime)
+	})
+
+	pageTimings := harPageTimings{OnContentLoad: -1, OnLoad: -1}
+	if !r.contentLoaded.IsZero() {
+		pageTimings.OnContentLoad = milliseconds(r.contentLoaded.Sub(r.started))
+	}
+	if !r.loaded.IsZero() {
+		pageTimings.OnLoad = milliseconds(r.loaded.Sub(r.started))
+	}
+
+	return json.MarshalIndent(harLog{Log: harContent{
+		Version: "1.2",
+		Creator: harCreator{Name: "spydr-crawler", Version: "1.0"},
+		Pages: []harPage{{
+			StartedDateTime: r.startedWall,
+			ID:              harPageID,
+			Title:           title,
+			PageTimings:     pageTimings,
+		}},
+		Entries: entries,
+	}}, "", "  ")
+}
+
+func newHARRequest(request *network.Request) harRequest {
+	harRequest := harRequest{
+		Method:      request.Method,
+		URL:         request.URL + request.URLFragment,
+		HTTPVersion: "HTTP/1.1",
+		Cookies:     []harNameValue{},
+		Headers:     harHeaders(request.Headers),
+		QueryString: []harNameValue{},
+		HeadersSize: -1,
+		BodySize:    -1,
+	}
+	if parsedUrl, err := url.Parse(request.URL); err == nil {
+		for name, values := range parsedUrl.Query() {
+			for _, value := range values {
+				harRequest.QueryString = append(harRequest.QueryString, harNameValue{Name: name, Value: value})
+			}
+		}
+		sort.Slice(harRequest.QueryString, func(i, j int) bool {
+			return harRequest.QueryString[i].Name < harRequest.QueryString[j].Name
+		})
+	}
+	if !request.HasPostData {
+		harRequest.BodySize = 0
+	}
+	return harRequest
+}
+
+// harTimingsFrom splits a request into HAR phases from Chrome's resource
+// timing, where every phase is an offset in milliseconds from requestTime.
+func harTimingsFrom(timing *network.ResourceTiming, total float64, finished time.Time) harTimings {
+	if timing == nil {
+		return harTimings{Blocked: -1, DNS: -1, Connect: -1, Send: 0, Wait: total, Receive: 0, SSL: -1}
+	}
+
+	span := func(start, end float64) float64 {
+		if start < 0 || end < 0 {
+			return -1
+		}
+		return end - start
+	}
+
+	timings := harTimings{
+		DNS:     span(timing.DNSStart, timing.DNSEnd),
+		Connect: span(timing.ConnectStart, timing.ConnectEnd),
+		SSL:     span(timing.SslStart, timing.SslEnd),
+		Send:    max(timing.SendEnd-timing.SendStart, 0),
+		Wait:    max(timing.ReceiveHeadersEnd-timing.SendEnd, 0),
+	}
+
+	blocked := timing.SendStart
+	for _, start := range []float64{timing.ConnectStart, timing.DNSStart} {
+		if start >= 0 {
+			blocked = start
+		}
+	}
+	timings.Blocked = max(blocked, 0)
+
+	finishedAt := float64(finished.Sub(*cdp.MonotonicTimeEpoch)) / float64(time.Millisecond)
+	timings.Receive = max(finishedAt-timing.RequestTime*1000-timing.ReceiveHeadersEnd, 0)
+
+	return timings
+}
+
+func harHeaders(headers network.Headers) []harNameValue {
+	values := make([]harNameValue, 0, len(headers))
+	for name, value := range headers {
+		for _, line := range strings.Split(fmt.Sprint(value), "\n") {
+			values = append(values, harNameValue{Name: name, Value: line})
+		}
+	}
+	sort.Slice(values, func(i, j int) bool { return values[i].Name < values[j].Name })
+	return values
+}
+
+func headerValue(headers network.Headers, name string) string {
+	for key, value := range headers {
+		if strings.EqualFold(key, name) {
+			return fmt.Sprint(value)
+		}
+	}
+	return ""
+}
+
+func httpVersion(protocol string) string {
+	switch strings.ToLower(protocol) {
+	case "h2":
+		return "HTTP/2.0"
+	case "h3", "h3-29", "quic":
+		return "HTTP/3.0"
+	case "http/1.0":
+		return "HTTP/1.0"
+	case "":
+		return "HTTP/1.1"
+	}
+	return strings.ToUpper(protocol)
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+func saveHAR(url string, data []byte) (string, error) {
+	name := fmt.Sprintf("%d-%v.har", time.Now().UnixNano(), urlToSlug(url))
+	if err := os.WriteFile(filepath.Join(os.Getenv("SCREENSHOT_DIR"), name), data, 0644); err != nil {
+		return "", err
+	}
+	return name, nil
+}
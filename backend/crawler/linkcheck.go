@@ -0,0 +1,291 @@
+package crawler
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	linkCheckConcurrency = 10
+	hostRequestInterval  = 250 * time.Millisecond
+	maxTrackedHosts      = 1000
+	maxRedirects         = 10
+)
+
+var (
+	ErrDeadlineExceeded = errors.New("job deadline exceeded")
+	errTooManyRedirects = errors.New("stopped after 10 redirects")
+)
+
+var defaultHostLimiter = newHostLimiter()
+
+type hostLimiter struct {
+	next  map[string]time.Time
+	mutex sync.Mutex
+}
+
+func newHostLimiter() *hostLimiter {
+	return &hostLimiter{next: make(map[string]time.Time)}
+}
+
+func (limiter *hostLimiter) Wait(ctx context.Context, host string, interval time.Duration) error {
+	limiter.mutex.Lock()
+	now := time.Now()
+	if len(limiter.next) > maxTrackedHosts {
+		for trackedHost, slot := range limiter.next {
+			if slot.Before(now) {
+				delete(limiter.next, trackedHost)
+			}
+		}
+	}
+
+	slot := limiter.next[host]
+	if slot.Before(now) {
+		slot = now
+	}
+	limiter.next[host] = slot.Add(interval)
+	limiter.mutex.Unlock()
+
+	return sleep(ctx, slot.Sub(now))
+}
+
+func sleep(ctx context.Context, duration time.Duration) error {
+	if duration <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+type linkStatus struct {
+	statusCode    int
+	statusClass   string
+	redirectChain []RedirectHop
+	err           string
+}
+
+type linkCheck struct {
+	done   chan struct{}
+	status linkStatus
+}
+
+func (s *session) throttle(ctx context.Context, rawURL string) error {
+	parsedUrl, err := url.Parse(rawURL)
+	if err != nil || parsedUrl.Host == "" {
+		return nil
+	}
+
+	interval := hostRequestInterval
+	if s.robots != nil {
+		if delay := s.robots.CrawlDelay(ctx, rawURL); delay > interval {
+			interval = delay
+		}
+	}
+	return s.limiter.Wait(ctx, strings.ToLower(parsedUrl.Host), interval)
+}
+
+func (s *session) checkLinks(ctx context.Context, urls []string) []linkStatus {
+	statuses := make([]linkStatus, len(urls))
+	semaphore := make(chan struct{}, linkCheckConcurrency)
+	var wg sync.WaitGroup
+
+	for i, link := range urls {
+		select {
+		case <-ctx.Done():
+			statuses[i] = skippedStatus(ctx)
+			continue
+		case semaphore <- struct{}{}:
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+			statuses[i] = s.checkLink(ctx, link)
+		}()
+	}
+	wg.Wait()
+
+	return statuses
+}
+
+func skippedStatus(ctx context.Context) linkStatus {
+	return linkStatus{statusClass: LinkStatusSkipped, err: context.Cause(ctx).Error()}
+}
+
+func (s *session) checkLink(ctx context.Context, link string) linkStatus {
+	key := normalizePageURL(link)
+
+	s.linkChecksMutex.Lock()
+	check, exists := s.linkChecks[key]
+	if !exists {
+		check = &linkCheck{done: make(chan struct{})}
+		s.linkChecks[key] = check
+	}
+	s.linkChecksMutex.Unlock()
+
+	if exists {
+		<-check.done
+		return check.status
+	}
+
+	check.status = s.runLinkCheck(ctx, link)
+	close(check.done)
+	return check.status
+}
+
+func (s *session) runLinkCheck(ctx context.Context, link string) linkStatus {
+	if ctx.Err() != nil {
+		return skippedStatus(ctx)
+	}
+	// An unreachable robots.txt says nothing about the link itself, so only a
+	// disallow rule stops the check.
+	if err := s.checkRobots(ctx, link); errors.Is(err, ErrBlockedByRobots) {
+		return linkStatus{statusClass: LinkStatusBlocked, err: ErrBlockedByRobots.Error()}
+	}
+
+	if err := s.throttle(ctx, link); err != nil {
+		return skippedStatus(ctx)
+	}
+	statusCode, chain, err := requestStatus(ctx, s.linkClient, http.MethodHead, link)
+	if shouldRetryWithGet(statusCode, err) && ctx.Err() == nil {
+		if err := s.throttle(ctx, link); err != nil {
+			return skippedStatus(ctx)
+		}
+		statusCode, chain, err = requestStatus(ctx, s.linkClient, http.MethodGet, link)
+	}
+	if ctx.Err() != nil {
+		return skippedStatus(ctx)
+	}
+
+	status := linkStatus{
+		statusCode:    statusCode,
+		statusClass:   linkStatusClass(statusCode, chain, err),
+		redirectChain: chain,
+	}
+	if err != nil {
+		status.err = err.Error()
+	}
+	return status
+}
+
+func requestStatus(ctx context.Context, client *http.Client, method string, link string) (int, []RedirectHop, error) {
+	var chain []RedirectHop
+	current := link
+
+	for hop := 0; hop <= maxRedirects; hop++ {
+		req, err := http.NewRequestWithContext(ctx, method, current, nil)
+		if err != nil {
+			return 0, chain, err
+		}
+		req.Header.Set("User-Agent", userAgent)
+		if method == http.MethodGet {
+			req.Header.Set("Range", "bytes=0-0")
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return 0, chain, err
+		}
+		io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+		resp.Body.Close()
+
+		location, err := resp.Location()
+		if !isRedirectStatus(resp.StatusCode) || err != nil {
+			if len(chain) > 0 {
+				chain = append(chain, RedirectHop{URL: current, StatusCode: resp.StatusCode})
+			}
+			return resp.StatusCode, chain, nil
+		}
+
+		chain = append(chain, RedirectHop{URL: current, StatusCode: resp.StatusCode})
+		current = location.String()
+	}
+
+	return 0, chain, errTooManyRedirects
+}
+
+func isRedirectStatus(statusCode int) bool {
+	switch statusCode {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusSeeOther,
+		http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		return true
+	}
+	return false
+}
+
+func shouldRetryWithGet(statusCode int, err error) bool {
+	if err != nil {
+		switch classifyError(err) {
+		case LinkStatusDNSFailure, LinkStatusTLSFailure, LinkStatusTimeout:
+			return false
+		}
+		return true
+	}
+	return statusCode >= 400
+}
+
+func linkStatusClass(statusCode int, chain []RedirectHop, err error) string {
+	switch {
+	case err != nil:
+		return classifyError(err)
+	case statusCode >= 500:
+		return LinkStatusServerError
+	case statusCode >= 400:
+		return LinkStatusClientError
+	case statusCode >= 300 || len(chain) > 0:
+		return LinkStatusRedirect
+	case statusCode == 0:
+		return LinkStatusError
+	default:
+		return LinkStatusOK
+	}
+}
+
+func classifyError(err error) string {
+	var dnsErr *net.DNSError
+	var netErr net.Error
+	var certErr *tls.CertificateVerificationError
+	var recordErr tls.RecordHeaderError
+	var unknownAuthorityErr x509.UnknownAuthorityError
+	var hostnameErr x509.HostnameError
+	var invalidCertErr x509.CertificateInvalidError
+
+	switch {
+	case errors.As(err, &dnsErr):
+		return LinkStatusDNSFailure
+	case errors.As(err, &certErr), errors.As(err, &recordErr),
+		errors.As(err, &unknownAuthorityErr), errors.As(err, &hostnameErr),
+		errors.As(err, &invalidCertErr):
+		return LinkStatusTLSFailure
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return LinkStatusTimeout
+	default:
+		return LinkStatusError
+	}
+}
+
+func IsBrokenClass(statusClass string) bool {
+	switch statusClass {
+	case LinkStatusOK, LinkStatusRedirect, LinkStatusBlocked, LinkStatusSkipped:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,92 @@
+package crawler
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+const maxOutlineHeadings = 500
+
+type Heading struct {
+	Level    int
+	Text     string
+	Children []*Heading
+}
+
+type OutlineIssue struct {
+	Rule    string
+	Message string
+}
+
+// HeadingOutline is the page's h1–h6 headings in document order, each nested
+// under the closest preceding heading of a higher level.
+type HeadingOutline struct {
+	Headings []*Heading
+	Issues   []OutlineIssue
+}
+
+func extractOutline(node *html.Node) HeadingOutline {
+	var outline HeadingOutline
+	var stack []*Heading
+	count, h1Count, previousLevel := 0, 0, 0
+
+	walkInDocumentOrder(node, func(n *html.Node) {
+		tag := strings.ToLower(n.Data)
+		if len(tag) != 2 || tag[0] != 'h' || tag[1] < '1' || tag[1] > '6' || count >= maxOutlineHeadings {
+			return
+		}
+		count++
+
+		heading := &Heading{Level: int(tag[1] - '0'), Text: truncate(headingText(n), 255)}
+		if heading.Level == 1 {
+			h1Count++
+		}
+		if heading.Text == "" {
+			outline.Issues = append(outline.Issues, OutlineIssue{Rule: "heading_empty", Message: fmt.Sprintf("Heading %d (h%d) has no text", count, heading.Level)})
+		}
+		if previousLevel > 0 && heading.Level > previousLevel+1 {
+			outline.Issues = append(outline.Issues, OutlineIssue{
+				Rule:    "heading_skipped",
+				Message: fmt.Sprintf("h%d %q follows h%d, skipping a level", heading.Level, heading.Text, previousLevel),
+			})
+		}
+		previousLevel = heading.Level
+
+		for len(stack) > 0 && stack[len(stack)-1].Level >= heading.Level {
+			stack = stack[:len(stack)-1]
+		}
+		if len(stack) == 0 {
+			outline.Headings = append(outline.Headings, heading)
+		} else {
+			parent := stack[len(stack)-1]
+			parent.Children = append(parent.Children, heading)
+		}
+		stack = append(stack, heading)
+	})
+
+	switch {
+	case count > 0 && h1Count == 0:
+		outline.Issues = append(outline.Issues, OutlineIssue{Rule: "h1_missing", Message: "Page has headings but no h1"})
+	case h1Count > 1:
+		outline.Issues = append(outline.Issues, OutlineIssue{Rule: "h1_multiple", Message: fmt.Sprintf("Page has %d h1 headings", h1Count)})
+	}
+
+	return outline
+}
+
+func headingText(n *html.Node) string {
+	if text := nodeText(n); text != "" {
+		return text
+	}
+	var alts []string
+	walkInDocumentOrder(n, func(child *html.Node) {
+		if strings.ToLower(child.Data) == "img" {
+			if alt := strings.TrimSpace(attribute(child, "alt")); alt != "" {
+				alts = append(alts, alt)
+			}
+		}
+	})
+	return strings.Join(alts, " ")
+}
@@ -0,0 +1,68 @@
+package crawler
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/chromedp"
+)
+
+const defaultPaperSize = "letter"
+
+// PaperSizes are portrait width and height in inches.
+var PaperSizes = map[string][2]float64{
+	"letter":  {8.5, 11},
+	"legal":   {8.5, 14},
+	"tabloid": {11, 17},
+	"a3":      {11.69, 16.54},
+	"a4":      {8.27, 11.69},
+	"a5":      {5.83, 8.27},
+}
+
+type PDFOptions struct {
+	PaperSize       string
+	Landscape       bool
+	PrintBackground bool
+}
+
+// PrintPDF renders url the same way as TakeScreenshot and prints it to a PDF
+// in SCREENSHOT_DIR, returning the file name.
+func PrintPDF(ctx context.Context, browser *BrowserPool, url string, screenshot ScreenshotOptions, options PDFOptions) (string, error) {
+	rendered, err := renderPage(ctx, browser, url, screenshot, renderTargets{pdf: &options})
+	if err != nil {
+		log.Printf("Error printing PDF %s: %v", url, err)
+		return "", err
+	}
+	return savePDF(url, rendered.pdf)
+}
+
+func printAction(buf *[]byte, options PDFOptions) chromedp.Action {
+	return chromedp.ActionFunc(func(ctx context.Context) error {
+		size, ok := PaperSizes[options.PaperSize]
+		if !ok {
+			size = PaperSizes[defaultPaperSize]
+		}
+
+		var err error
+		*buf, _, err = page.PrintToPDF().
+			WithPaperWidth(size[0]).
+			WithPaperHeight(size[1]).
+			WithLandscape(options.Landscape).
+			WithPrintBackground(options.PrintBackground).
+			Do(ctx)
+		return err
+	})
+}
+
+func savePDF(url string, data []byte) (string, error) {
+	name := fmt.Sprintf("%d-%v.pdf", time.Now().UnixNano(), urlToSlug(url))
+	if err := os.WriteFile(filepath.Join(os.Getenv("SCREENSHOT_DIR"), name), data, 0644); err != nil {
+		return "", err
+	}
+	return name, nil
+}
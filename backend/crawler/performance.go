@@ -0,0 +1,114 @@
+package crawler
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/chromedp/cdproto/network"
+)
+
+// performanceObserverScript runs before any page script so that layout
+// shifts, LCP candidates and long tasks are recorded from the start.
+const performanceObserverScript = `(() => {
+	const perf = window.__spydrPerf = { lcp: null, cls: null, longTasks: [], fcp: null };
+	const observe = (type, callback) => {
+		try {
+			new PerformanceObserver(list => list.getEntries().forEach(callback)).observe({ type, buffered: true });
+		} catch (e) {}
+	};
+	observe('largest-contentful-paint', entry => { perf.lcp = entry.startTime; });
+	observe('layout-shift', entry => { if (!entry.hadRecentInput) perf.cls = (perf.cls || 0) + entry.value; });
+	observe('longtask', entry => { perf.longTasks.push([entry.startTime, entry.duration]); });
+	observe('paint', entry => { if (entry.name === 'first-contentful-paint') perf.fcp = entry.startTime; });
+})()`
+
+const performanceReadScript = `(() => {
+	const perf = window.__spydrPerf || { lcp: null, cls: null, longTasks: [], fcp: null };
+	const nav = performance.getEntriesByType('navigation')[0];
+	const start = perf.fcp || 0;
+	let tbt = null;
+	if (PerformanceObserver.supportedEntryTypes.includes('longtask')) {
+		tbt = perf.longTasks.filter(([startTime]) => startTime >= start)
+			.reduce((total, [, duration]) => total + Math.max(0, duration - 50), 0);
+	}
+	return {
+		ttfb: nav ? nav.responseStart : null,
+		domContentLoaded: nav && nav.domContentLoadedEventEnd > 0 ? nav.domContentLoadedEventEnd : null,
+		load: nav && nav.loadEventEnd > 0 ? nav.loadEventEnd : null,
+		lcp: perf.lcp,
+		cls: PerformanceObserver.supportedEntryTypes.includes('layout-shift') ? (perf.cls || 0) : null,
+		tbt: tbt,
+	};
+})()`
+
+// PerformanceMetrics are page load timings in milliseconds, as measured by
+// the browser. Metrics the browser could not measure are nil.
+type PerformanceMetrics struct {
+	TTFB             *float64
+	DOMContentLoaded *float64
+	Load             *float64
+	LCP              *float64
+	CLS              *float64
+	TBT              *float64
+	TransferBytes    int64
+	Requests         int
+	RequestsByType   map[string]int
+}
+
+type pageTimings struct {
+	TTFB             *float64 `json:"ttfb"`
+	DOMContentLoaded *float64 `json:"domContentLoaded"`
+	Load             *float64 `json:"load"`
+	LCP              *float64 `json:"lcp"`
+	CLS              *float64 `json:"cls"`
+	TBT              *float64 `json:"tbt"`
+}
+
+type networkStats struct {
+	mutex          sync.Mutex
+	transferBytes  int64
+	requests       int
+	requestsByType map[string]int
+}
+
+func newNetworkStats() *networkStats {
+	return &networkStats{requestsByType: make(map[string]int)}
+}
+
+func (s *networkStats) listen(ev interface{}) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	switch event := ev.(type) {
+	case *network.EventRequestWillBeSent:
+		resourceType := strings.ToLower(event.Type.String())
+		if resourceType == "" {
+			resourceType = "other"
+		}
+		s.requests++
+		s.requestsByType[resourceType]++
+	case *network.EventLoadingFinished:
+		s.transferBytes += int64(event.EncodedDataLength)
+	}
+}
+
+func (s *networkStats) metrics(timings pageTimings) *PerformanceMetrics {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	metrics := &PerformanceMetrics{
+		TTFB:             timings.TTFB,
+		DOMContentLoaded: timings.DOMContentLoaded,
+		Load:             timings.Load,
+		LCP:              timings.LCP,
+		CLS:              timings.CLS,
+		TBT:              timings.TBT,
+	}
+	metrics.TransferBytes = s.transferBytes
+	metrics.Requests = s.requests
+	metrics.RequestsByType = make(map[string]int, len(s.requestsByType))
+	for resourceType, count := range s.requestsByType {
+		metrics.RequestsByType[resourceType] = count
+	}
+	return metrics
+}
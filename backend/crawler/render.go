@@ -0,0 +1,179 @@
+package crawler
+
+import (
+	"context"
+	"strings"
+
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/chromedp"
+	"golang.org/x/net/html"
+)
+
+const (
+	RenderStatic   = "static"
+	RenderRendered = "rendered"
+	RenderAuto     = "auto"
+
+	// minBodyText is how much visible text a static page needs before auto
+	// mode trusts it without rendering.
+	minBodyText = 200
+)
+
+type renderTargets struct {
+	dom        bool
+	screenshot bool
+	har        bool
+	// pdf is nil when no PDF is printed.
+	pdf       *PDFOptions
+	analyzers []BrowserAnalyzer
+}
+
+type renderedPage struct {
+	dom         string
+	screenshot  []byte
+	har         []byte
+	pdf         []byte
+	issues      []BrowserIssue
+	reports     map[string]*Report
+	performance *PerformanceMetrics
+}
+
+// renderPage loads url in a borrowed browser tab, waits as the options ask and
+// returns the post-JavaScript DOM, a screenshot and a HAR, as requested.
+func renderPage(ctx context.Context, browser *BrowserPool, url string, options ScreenshotOptions, targets renderTargets) (renderedPage, error) {
+	var rendered renderedPage
+	if browser == nil {
+		browser = sharedBrowserPool()
+	}
+	tabCtx, release, err := browser.Tab(ctx)
+	if err != nil {
+		return rendered, err
+	}
+	defer release()
+
+	collector := newIssueCollector()
+	chromedp.ListenTarget(tabCtx, collector.listen)
+
+	stats := newNetworkStats()
+	chromedp.ListenTarget(tabCtx, stats.listen)
+
+	var recorder *harRecorder
+	if targets.har {
+		recorder = newHARRecorder()
+		chromedp.ListenTarget(tabCtx, recorder.listen)
+	}
+
+	networkIdle := make(chan struct{})
+	if options.WaitUntil == WaitNetworkIdle {
+		var idle bool
+		chromedp.ListenTarget(tabCtx, func(ev interface{}) {
+			if event, ok := ev.(*page.EventLifecycleEvent); ok && event.Name == "networkIdle" && !idle {
+				idle = true
+				close(networkIdle)
+			}
+		})
+	}
+
+	actions := emulationActions(options)
+	actions = append(actions, chromedp.ActionFunc(func(ctx context.Context) error {
+		_, err := page.AddScriptToEvaluateOnNewDocument(performanceObserverScript).Do(ctx)
+		return err
+	}))
+	if options.WaitUntil == WaitNetworkIdle {
+		actions = append(actions, page.SetLifecycleEventsEnabled(true))
+	}
+	actions = append(actions, chromedp.Navigate(url))
+
+	if err := chromedp.Run(tabCtx, actions...); err != nil {
+		return rendered, err
+	}
+	if err := waitForPage(tabCtx, options, networkIdle); err != nil {
+		return rendered, err
+	}
+
+	var title string
+	var timings pageTimings
+	capture := []chromedp.Action{
+		chromedp.Title(&title),
+		chromedp.Evaluate(performanceReadScript, &timings),
+	}
+	if targets.dom {
+		capture = append(capture, chromedp.OuterHTML("html", &rendered.dom, chromedp.ByQuery))
+	}
+	if targets.screenshot {
+		capture = append(capture, captureAction(&rendered.screenshot, options))
+	}
+	if targets.pdf != nil {
+		capture = append(capture, printAction(&rendered.pdf, *targets.pdf))
+	}
+	if err := chromedp.Run(tabCtx, capture...); err != nil {
+		return rendered, err
+	}
+	rendered.reports = make(map[string]*Report, len(targets.analyzers))
+	for _, analyzer := range targets.analyzers {
+		report := newReport(analyzer.Name())
+		if err := analyzer.AnalyzeBrowser(tabCtx, url, report); err != nil {
+			if ctx.Err() != nil {
+				return rendered, ctx.Err()
+			}
+			report.AddFinding(Finding{Rule: "analyzer_failed", Severity: SeverityError, Message: err.Error()})
+		}
+		rendered.reports[analyzer.Name()] = report
+	}
+
+	rendered.issues = collector.result()
+	rendered.performance = stats.metrics(timings)
+
+	if recorder != nil {
+		har, err := recorder.marshal(title)
+		if err != nil {
+			return rendered, err
+		}
+		rendered.har = har
+	}
+
+	return rendered, ctx.Err()
+}
+
+func (s *session) shouldRender(result Result, links []Link, node *html.Node) bool {
+	switch s.options.RenderMode {
+	case RenderRendered:
+		return true
+	case RenderAuto:
+		return looksUnrendered(result, links, node)
+	}
+	return false
+}
+
+// looksUnrendered reports whether a static DOM is probably an empty
+// client-side app shell: scripts but little text and no headings or links.
+func looksUnrendered(result Result, links []Link, node *html.Node) bool {
+	headings := result.H1 + result.H2 + result.H3 + result.H4 + result.H5 + result.H6
+
+	hasScript := false
+	var text strings.Builder
+	walkThroughHtmlNodes(node, func(n *html.Node) {
+		if n.Type == html.ElementNode && strings.EqualFold(n.Data, "script") {
+			hasScript = true
+		}
+		if n.Type == html.TextNode && !insideInvisible(n) {
+			text.WriteString(strings.TrimSpace(n.Data))
+		}
+	})
+
+	if !hasScript {
+		return false
+	}
+	return text.Len() < minBodyText && (headings == 0 || len(links) == 0) ||
+		result.Title == "" && headings == 0 && len(links) == 0
+}
+
+func insideInvisible(n *html.Node) bool {
+	for parent := n.Parent; parent != nil; parent = parent.Parent {
+		switch strings.ToLower(parent.Data) {
+		case "script", "style", "noscript", "template", "head":
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,300 @@
+package crawler
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	robotsAgent         = "spydr-crawler"
+	robotsCacheTTL      = time.Hour
+	robotsErrorCacheTTL = time.Minute
+	robotsMaxBodySize   = 512 * 1024
+	maxCrawlDelay       = 30 * time.Second
+)
+
+var ErrBlockedByRobots = errors.New("blocked by robots.txt")
+
+var defaultRobotsCache = newRobotsCache()
+
+type robotsRule struct {
+	allow   bool
+	length  int
+	pattern *regexp.Regexp
+}
+
+type robotsRules struct {
+	rules      []robotsRule
+	crawlDelay time.Duration
+}
+
+// robotsEntry is a host's robots.txt, fetched once by the first caller while
+// later callers wait on done. err is set when the fetch failed.
+type robotsEntry struct {
+	done      chan struct{}
+	rules     robotsRules
+	err       error
+	expiresAt time.Time
+}
+
+func (entry *robotsEntry) expired() bool {
+	select {
+	case <-entry.done:
+		return !time.Now().Before(entry.expiresAt)
+	default:
+		return false
+	}
+}
+
+type robotsCache struct {
+	client  *http.Client
+	entries map[string]*robotsEntry
+	mutex   sync.Mutex
+}
+
+func newRobotsCache() *robotsCache {
+	return &robotsCache{
+		client:  &http.Client{Timeout: 10 * time.Second},
+		entries: make(map[string]*robotsEntry),
+	}
+}
+
+// Allowed reports whether robots.txt lets the crawler fetch rawURL. When
+// robots.txt could not be fetched, the URL is not allowed and the error is
+// transient, so the job is retried once the error entry expires.
+func (cache *robotsCache) Allowed(ctx context.Context, rawURL string) (bool, error) {
+	parsedUrl, err := url.Parse(rawURL)
+	if err != nil || parsedUrl.Host == "" {
+		return true, nil
+	}
+	if parsedUrl.Path == "/robots.txt" {
+		return true, nil
+	}
+	entry, err := cache.entry(ctx, parsedUrl)
+	if err != nil {
+		return false, err
+	}
+	if entry.err != nil {
+		return false, markTransient(fmt.Errorf("fetching robots.txt: %w", entry.err))
+	}
+	return entry.rules.allowed(robotsPath(parsedUrl)), nil
+}
+
+func (cache *robotsCache) CrawlDelay(ctx context.Context, rawURL string) time.Duration {
+	parsedUrl, err := url.Parse(rawURL)
+	if err != nil || parsedUrl.Host == "" {
+		return 0
+	}
+	entry, err := cache.entry(ctx, parsedUrl)
+	if err != nil {
+		return 0
+	}
+	return entry.rules.crawlDelay
+}
+
+func (cache *robotsCache) entry(ctx context.Context, parsedUrl *url.URL) (*robotsEntry, error) {
+	key := parsedUrl.Scheme + "://" + strings.ToLower(parsedUrl.Host)
+
+	cache.mutex.Lock()
+	entry, exists := cache.entries[key]
+	if exists && entry.expired() {
+		exists = false
+	}
+	if !exists {
+		entry = &robotsEntry{done: make(chan struct{})}
+		cache.entries[key] = entry
+	}
+	cache.mutex.Unlock()
+
+	if exists {
+		select {
+		case <-entry.done:
+			return entry, nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	// The fetch is shared with every caller waiting on this host, so it is
+	// not cut short when the first caller's job is cancelled.
+	var ttl time.Duration
+	entry.rules, ttl, entry.err = cache.fetch(context.WithoutCancel(ctx), key+"/robots.txt")
+	entry.expiresAt = time.Now().Add(ttl)
+	close(entry.done)
+	return entry, nil
+}
+
+func (cache *robotsCache) fetch(ctx context.Context, robotsURL string) (robotsRules, time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, robotsURL, nil)
+	if err != nil {
+		return robotsRules{}, robotsErrorCacheTTL, err
+	}
+	req.Header.Set("User-Agent", userAgent)
+
+	resp, err := cache.client.Do(req)
+	if err != nil {
+		return robotsRules{}, robotsErrorCacheTTL, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 500:
+		return robotsRules{}, robotsErrorCacheTTL, &StatusError{StatusCode: resp.StatusCode, Status: resp.Status}
+	case resp.StatusCode >= 400:
+		return robotsRules{}, robotsCacheTTL, nil
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, robotsMaxBodySize))
+	if err != nil {
+		return robotsRules{}, robotsErrorCacheTTL, err
+	}
+	return parseRobots(string(body), robotsAgent), robotsCacheTTL, nil
+}
+
+func robotsPath(parsedUrl *url.URL) string {
+	path := parsedUrl.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	if parsedUrl.RawQuery != "" {
+		path += "?" + parsedUrl.RawQuery
+	}
+	return path
+}
+
+func (rules robotsRules) allowed(path string) bool {
+	allowed := true
+	matchedLength := -1
+
+	for _, rule := range rules.rules {
+		if !rule.pattern.MatchString(path) {
+			continue
+		}
+		if rule.length > matchedLength || (rule.length == matchedLength && rule.allow) {
+			allowed = rule.allow
+			matchedLength = rule.length
+		}
+	}
+
+	return allowed
+}
+
+type robotsGroup struct {
+	agents     []string
+	rules      []robotsRule
+	crawlDelay time.Duration
+}
+
+func parseRobots(content string, agent string) robotsRules {
+	var groups []*robotsGroup
+	var current *robotsGroup
+	inRules := false
+
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if index := strings.Index(line, "#"); index >= 0 {
+			line = line[:index]
+		}
+
+		key, value, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "user-agent":
+			if current == nil || inRules {
+				current = &robotsGroup{}
+				groups = append(groups, current)
+				inRules = false
+			}
+			current.agents = append(current.agents, strings.ToLower(value))
+		case "allow", "disallow":
+			if current == nil {
+				continue
+			}
+			inRules = true
+			if value == "" {
+				continue
+			}
+			current.rules = append(current.rules, robotsRule{
+				allow:   key == "allow",
+				length:  len(value),
+				pattern: robotsPattern(value),
+			})
+		case "crawl-delay":
+			if current == nil {
+				continue
+			}
+			inRules = true
+			if seconds, err := strconv.ParseFloat(value, 64); err == nil && seconds > 0 {
+				delay := time.Duration(seconds * float64(time.Second))
+				if delay > maxCrawlDelay {
+					delay = maxCrawlDelay
+				}
+				current.crawlDelay = delay
+			}
+		}
+	}
+
+	agent = strings.ToLower(agent)
+	var specific, wildcard robotsRules
+	var hasSpecific bool
+
+	for _, group := range groups {
+		if group.matches(agent) {
+			mergeGroup(&specific, group)
+			hasSpecific = true
+		} else if group.matches("*") {
+			mergeGroup(&wildcard, group)
+		}
+	}
+
+	if hasSpecific {
+		return specific
+	}
+	return wildcard
+}
+
+func (group *robotsGroup) matches(agent string) bool {
+	for _, groupAgent := range group.agents {
+		if groupAgent == agent {
+			return true
+		}
+		if groupAgent != "" && groupAgent != "*" && agent != "*" && strings.HasPrefix(agent, groupAgent) {
+			return true
+		}
+	}
+	return false
+}
+
+func mergeGroup(rules *robotsRules, group *robotsGroup) {
+	rules.rules = append(rules.rules, group.rules...)
+	if group.crawlDelay > rules.crawlDelay {
+		rules.crawlDelay = group.crawlDelay
+	}
+}
+
+func robotsPattern(value string) *regexp.Regexp {
+	anchored := strings.HasSuffix(value, "$")
+	value = strings.TrimSuffix(value, "$")
+
+	pattern := strings.ReplaceAll(regexp.QuoteMeta(value), `\*`, ".*")
+	if anchored {
+		pattern += "$"
+	}
+	return regexp.MustCompile("^" + pattern)
+}
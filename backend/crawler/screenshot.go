@@ -2,6 +2,7 @@ package crawler
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"os"
@@ -10,27 +11,65 @@ import (
 	"strings"
 	"time"
 
+	"github.com/chromedp/cdproto/emulation"
+	"github.com/chromedp/cdproto/page"
 	"github.com/chromedp/chromedp"
+	"github.com/chromedp/chromedp/device"
 )
 
-func TakeScreenshot(url string) (string, error) {
-	dir := os.Getenv("SCREENSHOT_DIR")
-	ctx, cancel := chromedp.NewContext(context.Background())
-	defer cancel()
-
-	var buf []byte
-	err := chromedp.Run(ctx,
-		chromedp.Navigate(url),
-		chromedp.Sleep(2*time.Second),
-		chromedp.CaptureScreenshot(&buf),
-	)
+const (
+	FormatPNG  = "png"
+	FormatJPEG = "jpeg"
+	FormatWebP = "webp"
+
+	WaitLoad        = "load"
+	WaitNetworkIdle = "networkidle"
+
+	defaultScreenshotDelay = 2 * time.Second
+	defaultQuality         = 80
+	// maxWait caps each wait condition so a page that never settles still gets captured.
+	maxWait = 30 * time.Second
+)
+
+var Devices = map[string]device.Info{
+	"iphone-se":  device.IPhoneSE.Device(),
+	"iphone-15":  device.IPhone15.Device(),
+	"pixel-5":    device.Pixel5.Device(),
+	"galaxy-s9":  device.GalaxyS9.Device(),
+	"ipad":       device.IPadgen7.Device(),
+	"ipad-pro":   device.IPadPro11.Device(),
+	"ipad-mini":  device.IPadMini.Device(),
+	"pixel-2-xl": device.Pixel2XL.Device(),
+}
+
+type ScreenshotOptions struct {
+	FullPage          bool
+	Width             int
+	Height            int
+	Device            string
+	Mobile            bool
+	UserAgent         string
+	DeviceScaleFactor float64
+	Format            string
+	Quality           int
+	WaitUntil         string
+	WaitSelector      string
+	Delay             time.Duration
+}
+
+func TakeScreenshot(ctx context.Context, browser *BrowserPool, url string, options ScreenshotOptions) (string, error) {
+	rendered, err := renderPage(ctx, browser, url, options, renderTargets{screenshot: true})
 	if err != nil {
 		log.Printf("Error taking screenshot %s: %v", url, err)
 		return "", err
 	}
+	return saveScreenshot(url, rendered.screenshot, options)
+}
 
+func saveScreenshot(url string, buf []byte, options ScreenshotOptions) (string, error) {
+	dir := os.Getenv("SCREENSHOT_DIR")
 	slug := urlToSlug(url)
-	name := fmt.Sprintf("%d-%v.png", time.Now().UnixNano(), slug)
+	name := fmt.Sprintf("%d-%v.%s", time.Now().UnixNano(), slug, screenshotFormat(options))
 	path := filepath.Join(dir, name)
 
 	if err := os.WriteFile(path, buf, 0644); err != nil {
@@ -40,6 +79,141 @@ func TakeScreenshot(url string) (string, error) {
 
 	return name, nil
 }
+
+func emulationActions(options ScreenshotOptions) []chromedp.Action {
+	var actions []chromedp.Action
+
+	if info, ok := Devices[options.Device]; ok {
+		if options.Width > 0 {
+			info.Width = int64(options.Width)
+		}
+		if options.Height > 0 {
+			info.Height = int64(options.Height)
+		}
+		if options.DeviceScaleFactor > 0 {
+			info.Scale = options.DeviceScaleFactor
+		}
+		if options.Mobile {
+			info.Mobile, info.Touch = true, true
+		}
+		if options.UserAgent != "" {
+			info.UserAgent = options.UserAgent
+		}
+		return append(actions, chromedp.Emulate(info))
+	}
+
+	if options.Width > 0 || options.Height > 0 || options.Mobile || options.DeviceScaleFactor > 0 {
+		width, height := int64(options.Width), int64(options.Height)
+		if width <= 0 {
+			width = 1280
+		}
+		if height <= 0 {
+			height = 800
+		}
+		var viewportOptions []chromedp.EmulateViewportOption
+		if options.DeviceScaleFactor > 0 {
+			viewportOptions = append(viewportOptions, chromedp.EmulateScale(options.DeviceScaleFactor))
+		}
+		if options.Mobile {
+			viewportOptions = append(viewportOptions, chromedp.EmulateMobile, chromedp.EmulateTouch)
+		}
+		actions = append(actions, chromedp.EmulateViewport(width, height, viewportOptions...))
+	}
+
+	if options.UserAgent != "" {
+		actions = append(actions, emulation.SetUserAgentOverride(options.UserAgent))
+	}
+
+	return actions
+}
+
+func waitForPage(ctx context.Context, options ScreenshotOptions, networkIdle <-chan struct{}) error {
+	if options.WaitUntil == WaitNetworkIdle {
+		select {
+		case <-networkIdle:
+		case <-time.After(maxWait):
+			log.Printf("Network did not go idle within %s, capturing anyway", maxWait)
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	if options.WaitSelector != "" {
+		waitCtx, cancel := context.WithTimeout(ctx, maxWait)
+		err := chromedp.Run(waitCtx, chromedp.WaitVisible(options.WaitSelector, chromedp.ByQuery))
+		cancel()
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if !errors.Is(err, context.DeadlineExceeded) {
+				return err
+			}
+			log.Printf("Selector %q did not appear within %s, capturing anyway", options.WaitSelector, maxWait)
+		}
+	}
+
+	delay := options.Delay
+	if delay <= 0 && options.WaitUntil == "" && options.WaitSelector == "" {
+		delay = defaultScreenshotDelay
+	}
+	return sleep(ctx, delay)
+}
+
+func captureAction(buf *[]byte, options ScreenshotOptions) chromedp.Action {
+	return chromedp.ActionFunc(func(ctx context.Context) error {
+		capture := page.CaptureScreenshot().WithFromSurface(true)
+
+		switch screenshotFormat(options) {
+		case FormatJPEG:
+			capture = capture.WithFormat(page.CaptureScreenshotFormatJpeg).WithQuality(screenshotQuality(options))
+		case FormatWebP:
+			capture = capture.WithFormat(page.CaptureScreenshotFormatWebp).WithQuality(screenshotQuality(options))
+		default:
+			capture = capture.WithFormat(page.CaptureScreenshotFormatPng)
+		}
+		if options.FullPage {
+			capture = capture.WithCaptureBeyondViewport(true)
+			_, _, _, _, _, contentSize, err := page.GetLayoutMetrics().Do(ctx)
+			if err == nil && contentSize != nil {
+				capture = capture.WithClip(&page.Viewport{
+					Width:  contentSize.Width,
+					Height: contentSize.Height,
+					Scale:  1,
+				})
+			}
+		}
+
+		var err error
+		*buf, err = capture.Do(ctx)
+		return err
+	})
+}
+
+func screenshotFormat(options ScreenshotOptions) string {
+	switch options.Format {
+	case FormatJPEG, FormatWebP:
+		return options.Format
+	}
+	return FormatPNG
+}
+
+func screenshotQuality(options ScreenshotOptions) int64 {
+	if options.Quality <= 0 || options.Quality > 100 {
+		return defaultQuality
+	}
+	return int64(options.Quality)
+}
+
+func removeScreenshot(name string) {
+	if name == "" {
+		return
+	}
+	path := filepath.Join(os.Getenv("SCREENSHOT_DIR"), name)
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		log.Printf("Error removing screenshot %s: %v", path, err)
+	}
+}
 func urlToSlug(url string) string {
 	slug := regexp.MustCompile(`^https?://`).ReplaceAllString(url, "")
 
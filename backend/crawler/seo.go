@@ -0,0 +1,225 @@
+package crawler
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+)
+
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+	SeverityInfo    = "info"
+
+	minTitleLength       = 10
+	maxTitleLength       = 60
+	minDescriptionLength = 50
+	maxDescriptionLength = 160
+
+	maxSocialTags = 50
+)
+
+// setSocialTag records an OpenGraph or Twitter card tag, keeping at most
+// maxSocialTags distinct tags per page.
+func setSocialTag(tags map[string]string, key, content string) {
+	key = truncate(key, 255)
+	if _, ok := tags[key]; !ok && len(tags) >= maxSocialTags {
+		return
+	}
+	tags[key] = truncate(content, 1024)
+}
+
+var twitterCardTypes = map[string]bool{
+	"summary":             true,
+	"summary_large_image": true,
+	"app":                 true,
+	"player":              true,
+}
+
+// SEOMetadata is the search and social metadata of a page. OpenGraph and
+// TwitterCard are keyed by the full property name, e.g. "og:title".
+type SEOMetadata struct {
+	Lang             string
+	Description      string
+	DescriptionCount int
+	Robots           string
+	XRobotsTag       string
+	Canonical        string
+	CanonicalCount   int
+	Viewport         string
+	Favicon          string
+	OpenGraph        map[string]string
+	TwitterCard      map[string]string
+}
+
+func extractSEO(node *html.Node, pageURL string, header http.Header) SEOMetadata {
+	meta := SEOMetadata{
+		XRobotsTag:  strings.Join(header.Values("X-Robots-Tag"), ", "),
+		OpenGraph:   make(map[string]string),
+		TwitterCard: make(map[string]string),
+	}
+	var touchIcon string
+
+	walkThroughHtmlNodes(node, func(n *html.Node) {
+		if n.Type != html.ElementNode {
+			return
+		}
+
+		switch strings.ToLower(n.Data) {
+		case "html":
+			meta.Lang = strings.TrimSpace(attribute(n, "lang"))
+		case "meta":
+			name := strings.ToLower(strings.TrimSpace(attribute(n, "name")))
+			property := strings.ToLower(strings.TrimSpace(attribute(n, "property")))
+			content := strings.TrimSpace(attribute(n, "content"))
+			switch {
+			case name == "description":
+				meta.DescriptionCount++
+				if meta.DescriptionCount == 1 {
+					meta.Description = truncate(content, 1024)
+				}
+			case name == "robots":
+				meta.Robots = truncate(content, 255)
+			case name == "viewport":
+				meta.Viewport = truncate(content, 255)
+			case strings.HasPrefix(property, "og:"):
+				setSocialTag(meta.OpenGraph, property, content)
+			case strings.HasPrefix(name, "twitter:"):
+				setSocialTag(meta.TwitterCard, name, content)
+			case strings.HasPrefix(property, "twitter:"):
+				setSocialTag(meta.TwitterCard, property, content)
+			}
+		case "link":
+			href := strings.TrimSpace(attribute(n, "href"))
+			for _, rel := range strings.Fields(strings.ToLower(attribute(n, "rel"))) {
+				switch rel {
+				case "canonical":
+					meta.CanonicalCount++
+					if meta.CanonicalCount == 1 && href != "" {
+						meta.Canonical = truncate(absoluteURL(href, pageURL), 2048)
+					}
+				case "icon":
+					if meta.Favicon == "" && href != "" {
+						meta.Favicon = truncate(absoluteURL(href, pageURL), 2048)
+					}
+				case "apple-touch-icon":
+					if touchIcon == "" && href != "" {
+						touchIcon = truncate(absoluteURL(href, pageURL), 2048)
+					}
+				}
+			}
+		}
+	})
+
+	if meta.Favicon == "" {
+		meta.Favicon = touchIcon
+	}
+	return meta
+}
+
+func attribute(n *html.Node, key string) string {
+	for _, attr := range n.Attr {
+		if strings.EqualFold(attr.Key, key) {
+			return attr.Val
+		}
+	}
+	return ""
+}
+
+// checkSEO validates a page's metadata against common SEO rules.
+func checkSEO(title string, meta SEOMetadata, pageURL string) []Finding {
+	var findings []Finding
+	add := func(rule, severity, format string, args ...interface{}) {
+		findings = append(findings, Finding{Rule: rule, Severity: severity, Message: fmt.Sprintf(format, args...)})
+	}
+
+	switch length := utf8.RuneCountInString(title); {
+	case length == 0:
+		add("title_missing", SeverityError, "Page has no title")
+	case length < minTitleLength:
+		add("title_too_short", SeverityWarning, "Title is %d characters, shorter than %d", length, minTitleLength)
+	case length > maxTitleLength:
+		add("title_too_long", SeverityWarning, "Title is %d characters, longer than %d", length, maxTitleLength)
+	}
+
+	switch length := utf8.RuneCountInString(meta.Description); {
+	case meta.DescriptionCount == 0 || length == 0:
+		add("description_missing", SeverityWarning, "Page has no meta description")
+	case length < minDescriptionLength:
+		add("description_too_short", SeverityWarning, "Meta description is %d characters, shorter than %d", length, minDescriptionLength)
+	case length > maxDescriptionLength:
+		add("description_too_long", SeverityWarning, "Meta description is %d characters, longer than %d", length, maxDescriptionLength)
+	}
+	if meta.DescriptionCount > 1 {
+		add("description_duplicate", SeverityWarning, "Page has %d meta descriptions", meta.DescriptionCount)
+	}
+
+	switch {
+	case meta.CanonicalCount == 0:
+		add("canonical_missing", SeverityWarning, "Page has no canonical URL")
+	case meta.CanonicalCount > 1:
+		add("canonical_duplicate", SeverityError, "Page has %d canonical links", meta.CanonicalCount)
+	}
+	if meta.CanonicalCount > 0 && !isCrawlableURL(meta.Canonical) {
+		add("canonical_invalid", SeverityError, "Canonical URL %q is not an absolute http(s) URL", meta.Canonical)
+	}
+
+	if directive := noindexDirective(meta); directive != "" && !canonicalizedElsewhere(meta.Canonical, pageURL) {
+		add("noindex", SeverityError, "Page is excluded from search results by %s", directive)
+	}
+
+	if meta.Viewport == "" {
+		add("viewport_missing", SeverityWarning, "Page has no viewport meta tag")
+	}
+	if meta.Lang == "" {
+		add("lang_missing", SeverityWarning, "The html element has no lang attribute")
+	}
+	if meta.Favicon == "" {
+		add("favicon_missing", SeverityInfo, "Page does not declare a favicon")
+	}
+
+	if missing := missingTags(meta.OpenGraph, "og:title", "og:description", "og:image", "og:url"); len(missing) > 0 {
+		add("opengraph_incomplete", SeverityInfo, "Missing OpenGraph tags: %s", strings.Join(missing, ", "))
+	}
+	switch card := meta.TwitterCard["twitter:card"]; {
+	case card == "":
+		add("twitter_card_missing", SeverityInfo, "Page has no twitter:card tag")
+	case !twitterCardTypes[card]:
+		add("twitter_card_invalid", SeverityWarning, "Unknown twitter:card type %q", card)
+	}
+
+	return findings
+}
+
+func noindexDirective(meta SEOMetadata) string {
+	for _, source := range []struct{ name, value string }{
+		{"meta robots", meta.Robots},
+		{"X-Robots-Tag", meta.XRobotsTag},
+	} {
+		for _, directive := range strings.FieldsFunc(strings.ToLower(source.value), func(r rune) bool { return r == ',' || r == ' ' }) {
+			if directive == "noindex" || directive == "none" {
+				return source.name + " " + directive
+			}
+		}
+	}
+	return ""
+}
+
+// canonicalizedElsewhere reports whether the page points search engines at
+// another URL, in which case a noindex is expected.
+func canonicalizedElsewhere(canonical, pageURL string) bool {
+	return canonical != "" && normalizePageURL(canonical) != normalizePageURL(pageURL)
+}
+
+func missingTags(tags map[string]string, names ...string) []string {
+	var missing []string
+	for _, name := range names {
+		if tags[name] == "" {
+			missing = append(missing, name)
+		}
+	}
+	return missing
+}
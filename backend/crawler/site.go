@@ -0,0 +1,229 @@
+package crawler
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+)
+
+const defaultMaxPages = 100
+
+type queuedPage struct {
+	url   string
+	depth int
+}
+
+type Page struct {
+	URL    string
+	Depth  int
+	Result Result
+	Err    error
+}
+
+func CrawlSite(ctx context.Context, targetURL string, options Options) (Result, []Page, error) {
+	timeout := options.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	jobCtx, cancel := context.WithTimeoutCause(ctx, timeout, ErrDeadlineExceeded)
+	defer cancel()
+
+	s := newSession(options)
+	result, links, err := s.crawlPage(jobCtx, targetURL, true)
+	if err != nil {
+		if ctx.Err() != nil {
+			return Result{}, nil, ctx.Err()
+		}
+		if errors.Is(context.Cause(jobCtx), ErrDeadlineExceeded) {
+			return Result{}, nil, ErrDeadlineExceeded
+		}
+		return Result{}, nil, err
+	}
+
+	pages := s.crawlLinkedPages(jobCtx, &result, links, targetURL)
+
+	if ctx.Err() != nil {
+		removeArtifacts(result)
+		return Result{}, nil, ctx.Err()
+	}
+	return result, pages, nil
+}
+
+// removeArtifacts deletes the screenshot, PDF and HAR files written for a
+// result that is being discarded.
+func removeArtifacts(result Result) {
+	removeScreenshot(result.ScreenshotPath)
+	removeScreenshot(result.HARPath)
+	removeScreenshot(result.PDFPath)
+}
+
+func (s *session) crawlLinkedPages(ctx context.Context, result *Result, links []Link, targetURL string) []Page {
+	options := s.options
+	if options.MaxDepth <= 0 {
+		return nil
+	}
+
+	maxPages := options.MaxPages
+	if maxPages <= 0 {
+		maxPages = defaultMaxPages
+	}
+
+	visited := map[string]bool{normalizePageURL(targetURL): true}
+	queue := enqueueLinks(nil, visited, links, targetURL, 1)
+	var pages []Page
+
+	for len(queue) > 0 && len(pages)+1 < maxPages {
+		if ctx.Err() != nil {
+			result.Incomplete = true
+			break
+		}
+
+		current := queue[0]
+		queue = queue[1:]
+
+		pageResult, pageLinks, err := s.crawlPage(ctx, current.url, false)
+		if err != nil && ctx.Err() != nil {
+			result.Incomplete = true
+			break
+		}
+		pages = append(pages, Page{
+			URL:    current.url,
+			Depth:  current.depth,
+			Result: pageResult,
+			Err:    err,
+		})
+		if pageResult.Incomplete {
+			result.Incomplete = true
+		}
+
+		if err == nil && current.depth < options.MaxDepth {
+			queue = enqueueLinks(queue, visited, pageLinks, current.url, current.depth+1)
+		}
+	}
+
+	return pages
+}
+
+func (s *session) crawlPage(ctx context.Context, targetURL string, withScreenshot bool) (_ Result, _ []Link, err error) {
+	response, err := s.fetchWebpage(ctx, targetURL)
+	if err != nil {
+		return Result{}, nil, err
+	}
+
+	node, err := parseHTML(response.Body)
+	if err != nil {
+		return Result{}, nil, err
+	}
+
+	// Page info is read here only to decide whether to render; the page-info
+	// analyzer fills it into the result.
+	static := extractPageInfo(node)
+	links := extractLinks(node)
+	result := Result{RenderMode: RenderStatic}
+	defer func() {
+		if err != nil {
+			removeArtifacts(result)
+		}
+	}()
+
+	targets := renderTargets{
+		dom:        s.shouldRender(static, links, node),
+		screenshot: withScreenshot,
+		har:        withScreenshot && s.options.RecordHAR,
+		analyzers:  s.browserAnalyzers(),
+	}
+	if withScreenshot {
+		targets.pdf = s.options.PDF
+	}
+	var reports map[string]*Report
+	if targets.dom || targets.screenshot {
+		rendered, err := renderPage(ctx, s.options.Browser, targetURL, s.options.Screenshot, targets)
+		if err != nil {
+			return Result{}, nil, markTransient(err)
+		}
+
+		if targets.dom {
+			if node, err = parseHTML(rendered.dom); err != nil {
+				return Result{}, nil, err
+			}
+			links = extractLinks(node)
+			result.RenderMode = RenderRendered
+		}
+		if targets.screenshot {
+			if result.ScreenshotPath, err = saveScreenshot(targetURL, rendered.screenshot, s.options.Screenshot); err != nil {
+				return Result{}, nil, markTransient(err)
+			}
+		}
+		if targets.pdf != nil {
+			if result.PDFPath, err = savePDF(targetURL, rendered.pdf); err != nil {
+				return Result{}, nil, markTransient(err)
+			}
+		}
+		if targets.har {
+			if result.HARPath, err = saveHAR(targetURL, rendered.har); err != nil {
+				return Result{}, nil, markTransient(err)
+			}
+		}
+		result.BrowserIssues = rendered.issues
+		result.Performance = rendered.performance
+		result.ConsoleErrors, result.FailedRequests = countIssues(rendered.issues)
+		reports = rendered.reports
+	}
+
+	s.runAnalyzers(ctx, &PageInput{
+		URL:       targetURL,
+		Response:  response,
+		DOM:       node,
+		Links:     links,
+		Rendered:  result.RenderMode == RenderRendered,
+		InBrowser: reports != nil,
+		result:    &result,
+		session:   s,
+	}, reports)
+
+	return result, links, nil
+}
+
+func enqueueLinks(queue []queuedPage, visited map[string]bool, links []Link, pageURL string, depth int) []queuedPage {
+	baseHost := hostOf(pageURL)
+	for _, link := range links {
+		if isSkippableLink(link.Href) || !isInternal(link.Href, baseHost) {
+			continue
+		}
+
+		absoluteLink := absoluteURL(link.Href, pageURL)
+		if !isCrawlableURL(absoluteLink) {
+			continue
+		}
+
+		key := normalizePageURL(absoluteLink)
+		if visited[key] {
+			continue
+		}
+		visited[key] = true
+		queue = append(queue, queuedPage{url: absoluteLink, depth: depth})
+	}
+	return queue
+}
+
+func isCrawlableURL(raw string) bool {
+	parsedUrl, err := url.Parse(raw)
+	if err != nil {
+		return false
+	}
+	return parsedUrl.Scheme == "http" || parsedUrl.Scheme == "https"
+}
+
+func normalizePageURL(raw string) string {
+	parsedUrl, err := url.Parse(raw)
+	if err != nil {
+		return raw
+	}
+	parsedUrl.Fragment = ""
+	parsedUrl.Host = strings.ToLower(parsedUrl.Host)
+	if parsedUrl.Path == "" {
+		parsedUrl.Path = "/"
+	}
+	return parsedUrl.String()
+}
@@ -0,0 +1,362 @@
+package crawler
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+const (
+	FormatJSONLD    = "json-ld"
+	FormatMicrodata = "microdata"
+	FormatRDFa      = "rdfa"
+
+	maxStructuredItems = 50
+	schemaOrgContext   = "https://schema.org"
+)
+
+// requiredProperties lists the schema.org properties a type needs to be
+// eligible for rich results. Alternatives are separated by "|".
+var requiredProperties = map[string][]string{
+	"Article":             {"headline"},
+	"NewsArticle":         {"headline"},
+	"BlogPosting":         {"headline"},
+	"Product":             {"name", "offers|review|aggregateRating"},
+	"Offer":               {"price|priceSpecification", "priceCurrency|priceSpecification"},
+	"AggregateOffer":      {"lowPrice", "priceCurrency"},
+	"AggregateRating":     {"ratingValue", "ratingCount|reviewCount"},
+	"Review":              {"author", "reviewRating|reviewBody"},
+	"Rating":              {"ratingValue"},
+	"Organization":        {"name"},
+	"LocalBusiness":       {"name", "address"},
+	"Person":              {"name"},
+	"BreadcrumbList":      {"itemListElement"},
+	"ListItem":            {"position"},
+	"Event":               {"name", "startDate", "location"},
+	"Recipe":              {"name", "image"},
+	"FAQPage":             {"mainEntity"},
+	"Question":            {"name", "acceptedAnswer|suggestedAnswer"},
+	"Answer":              {"text"},
+	"HowTo":               {"name", "step"},
+	"VideoObject":         {"name", "thumbnailUrl", "uploadDate"},
+	"JobPosting":          {"title", "description", "datePosted", "hiringOrganization"},
+	"Course":              {"name", "description"},
+	"SoftwareApplication": {"name", "offers|aggregateRating|review"},
+	"WebSite":             {"url"},
+}
+
+// StructuredItem is one top-level structured data item, normalized to a
+// JSON-LD style object whatever syntax it was written in.
+type StructuredItem struct {
+	Format string
+	Type   string
+	Data   map[string]interface{}
+	Errors []string
+}
+
+func extractStructuredData(node *html.Node, pageURL string) []StructuredItem {
+	var items []StructuredItem
+	add := func(item StructuredItem) {
+		if len(items) < maxStructuredItems {
+			items = append(items, item)
+		}
+	}
+
+	walkThroughHtmlNodes(node, func(n *html.Node) {
+		if n.Type != html.ElementNode {
+			return
+		}
+
+		switch {
+		case strings.ToLower(n.Data) == "script" && strings.EqualFold(strings.TrimSpace(attribute(n, "type")), "application/ld+json"):
+			for _, item := range parseJSONLD(nodeRawText(n)) {
+				add(item)
+			}
+		case hasAttribute(n, "itemscope") && !hasAttribute(n, "itemprop"):
+			data := microdata.item(n, pageURL)
+			add(newStructuredItem(FormatMicrodata, data))
+		case hasAttribute(n, "typeof") && !hasAttribute(n, "property"):
+			data := rdfa.item(n, pageURL)
+			add(newStructuredItem(FormatRDFa, data))
+		}
+	})
+
+	return items
+}
+
+func newStructuredItem(format string, data map[string]interface{}) StructuredItem {
+	item := StructuredItem{Format: format, Data: data, Type: truncate(strings.Join(schemaTypes(data["@type"]), ","), 255)}
+	if data["@type"] == nil {
+		item.Errors = append(item.Errors, "item has no @type")
+	}
+	path := item.Type
+	if path == "" {
+		path = "item"
+	}
+	item.Errors = append(item.Errors, validateSchema(data, path)...)
+	return item
+}
+
+func parseJSONLD(source string) []StructuredItem {
+	var document interface{}
+	if err := json.Unmarshal([]byte(strings.TrimSpace(source)), &document); err != nil {
+		return []StructuredItem{{Format: FormatJSONLD, Errors: []string{fmt.Sprintf("invalid JSON: %v", err)}}}
+	}
+
+	var objects []map[string]interface{}
+	var sharedContext interface{}
+	var collect func(value interface{})
+	collect = func(value interface{}) {
+		switch value := value.(type) {
+		case []interface{}:
+			for _, element := range value {
+				collect(element)
+			}
+		case map[string]interface{}:
+			if value["@context"] != nil && sharedContext == nil {
+				sharedContext = value["@context"]
+			}
+			if graph, ok := value["@graph"]; ok {
+				collect(graph)
+				return
+			}
+			objects = append(objects, value)
+		}
+	}
+	collect(document)
+
+	if len(objects) == 0 {
+		return []StructuredItem{{Format: FormatJSONLD, Errors: []string{"JSON-LD contains no objects"}}}
+	}
+
+	items := make([]StructuredItem, 0, len(objects))
+	for _, object := range objects {
+		if object["@context"] == nil && sharedContext != nil {
+			object["@context"] = sharedContext
+		}
+		item := newStructuredItem(FormatJSONLD, object)
+		if object["@context"] == nil {
+			item.Errors = append([]string{"item has no @context"}, item.Errors...)
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// itemSyntax describes how an attribute-based syntax marks items, types and
+// properties, so microdata and RDFa Lite share one parser.
+type itemSyntax struct {
+	scope    string
+	typeAttr string
+	idAttr   string
+	property string
+	// contentFirst reads a content attribute on any element before the
+	// element's own value, as RDFa does.
+	contentFirst bool
+}
+
+var (
+	microdata = itemSyntax{scope: "itemscope", typeAttr: "itemtype", idAttr: "itemid", property: "itemprop"}
+	rdfa      = itemSyntax{scope: "typeof", typeAttr: "typeof", idAttr: "resource", property: "property", contentFirst: true}
+)
+
+func (syntax itemSyntax) item(node *html.Node, pageURL string) map[string]interface{} {
+	data := make(map[string]interface{})
+
+	types := strings.Fields(attribute(node, syntax.typeAttr))
+	if len(types) > 0 {
+		data["@context"] = schemaOrgContext
+		if len(types) == 1 {
+			data["@type"] = schemaTypes(types[0])[0]
+		} else {
+			data["@type"] = schemaTypes(types)
+		}
+	}
+	if id := attribute(node, syntax.idAttr); id != "" {
+		data["@id"] = absoluteURL(id, pageURL)
+	}
+
+	var walk func(*html.Node)
+	walk = func(parent *html.Node) {
+		for child := parent.FirstChild; child != nil; child = child.NextSibling {
+			if child.Type != html.ElementNode {
+				continue
+			}
+
+			names := strings.Fields(attribute(child, syntax.property))
+			nested := hasAttribute(child, syntax.scope)
+			if len(names) > 0 {
+				var value interface{}
+				if nested {
+					item := syntax.item(child, pageURL)
+					delete(item, "@context")
+					value = item
+				} else {
+					value = syntax.value(child, pageURL)
+				}
+				for _, name := range names {
+					addProperty(data, schemaProperty(name), value)
+				}
+			}
+			if !nested {
+				walk(child)
+			}
+		}
+	}
+	walk(node)
+
+	return data
+}
+
+func (syntax itemSyntax) value(node *html.Node, pageURL string) string {
+	if syntax.contentFirst && hasAttribute(node, "content") {
+		return attribute(node, "content")
+	}
+
+	switch strings.ToLower(node.Data) {
+	case "meta":
+		return attribute(node, "content")
+	case "audio", "embed", "iframe", "img", "source", "track", "video":
+		return absoluteURL(attribute(node, "src"), pageURL)
+	case "a", "area", "link":
+		return absoluteURL(attribute(node, "href"), pageURL)
+	case "object":
+		return absoluteURL(attribute(node, "data"), pageURL)
+	case "data", "meter":
+		return attribute(node, "value")
+	case "time":
+		if datetime := attribute(node, "datetime"); datetime != "" {
+			return datetime
+		}
+	}
+	if syntax.contentFirst {
+		for _, key := range []string{"href", "src", "resource"} {
+			if value := attribute(node, key); value != "" {
+				return absoluteURL(value, pageURL)
+			}
+		}
+	}
+	return truncate(nodeText(node), 2048)
+}
+
+func addProperty(data map[string]interface{}, name string, value interface{}) {
+	switch existing := data[name].(type) {
+	case nil:
+		data[name] = value
+	case []interface{}:
+		data[name] = append(existing, value)
+	default:
+		data[name] = []interface{}{existing, value}
+	}
+}
+
+// validateSchema checks an object and the objects nested in it against
+// requiredProperties.
+func validateSchema(data map[string]interface{}, path string) []string {
+	var errors []string
+
+	for _, schemaType := range schemaTypes(data["@type"]) {
+		for _, requirement := range requiredProperties[schemaType] {
+			alternatives := strings.Split(requirement, "|")
+			if !hasAnyProperty(data, alternatives) {
+				message := fmt.Sprintf("%s is missing required property %q", path, alternatives[0])
+				if len(alternatives) > 1 {
+					message += fmt.Sprintf(" (or %s)", strings.Join(alternatives[1:], ", "))
+				}
+				errors = append(errors, message)
+			}
+		}
+	}
+
+	names := make([]string, 0, len(data))
+	for name := range data {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		var values []interface{}
+		if list, ok := data[name].([]interface{}); ok {
+			values = list
+		} else {
+			values = []interface{}{data[name]}
+		}
+		for _, value := range values {
+			if object, ok := value.(map[string]interface{}); ok {
+				errors = append(errors, validateSchema(object, path+"."+name)...)
+			}
+		}
+	}
+
+	return errors
+}
+
+func hasAnyProperty(data map[string]interface{}, names []string) bool {
+	for _, name := range names {
+		switch value := data[name].(type) {
+		case nil:
+		case string:
+			if strings.TrimSpace(value) != "" {
+				return true
+			}
+		case []interface{}:
+			if len(value) > 0 {
+				return true
+			}
+		default:
+			return true
+		}
+	}
+	return false
+}
+
+// schemaTypes returns the bare type names of an @type value, so
+// "https://schema.org/Product" and "schema:Product" both become "Product".
+func schemaTypes(value interface{}) []string {
+	var types []string
+	switch value := value.(type) {
+	case string:
+		types = append(types, schemaProperty(value))
+	case []string:
+		for _, element := range value {
+			types = append(types, schemaProperty(element))
+		}
+	case []interface{}:
+		for _, element := range value {
+			if name, ok := element.(string); ok {
+				types = append(types, schemaProperty(name))
+			}
+		}
+	}
+	return types
+}
+
+func schemaProperty(name string) string {
+	name = strings.TrimSpace(name)
+	if index := strings.LastIndexAny(name, "/#:"); index >= 0 {
+		return name[index+1:]
+	}
+	return name
+}
+
+func hasAttribute(n *html.Node, key string) bool {
+	for _, attr := range n.Attr {
+		if strings.EqualFold(attr.Key, key) {
+			return true
+		}
+	}
+	return false
+}
+
+func nodeRawText(n *html.Node) string {
+	var builder strings.Builder
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type == html.TextNode {
+			builder.WriteString(child.Data)
+		}
+	}
+	return builder.String()
+}
@@ -17,9 +17,28 @@ func ConnectToDB(dsn string) *gorm.DB {
 }
 func AutoMigrate(db *gorm.DB) {
 	log.Println("Running database migrations")
-	err := db.AutoMigrate(&models.CrawlJob{})
+	err := db.AutoMigrate(&models.CrawlJob{}, &models.CrawlPage{}, &models.CrawlLink{}, &models.CrawlBrowserIssue{}, &models.CrawlStructuredData{}, &models.CrawlFinding{}, &models.CrawlMetric{}, &models.CrawlAttempt{}, &models.CrawlSchedule{}, &models.ScreenshotBaseline{})
 	if err != nil {
 		log.Fatalf("Failed to migrate database: %v", err)
 	}
+	backfillNormalizedURLs(db)
 	log.Println("Database migrations completed")
 }
+
+func backfillNormalizedURLs(db *gorm.DB) {
+	var jobs []models.CrawlJob
+	err := db.Unscoped().Select("id", "url").Where("normalized_url = '' OR normalized_url IS NULL").
+		FindInBatches(&jobs, 500, func(tx *gorm.DB, batch int) error {
+			for _, job := range jobs {
+				err := db.Unscoped().Model(&models.CrawlJob{}).Where("id = ?", job.ID).
+					UpdateColumn("normalized_url", models.NormalizeURL(job.URL)).Error
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		}).Error
+	if err != nil {
+		log.Printf("Failed to backfill normalized URLs: %v", err)
+	}
+}
@@ -0,0 +1,143 @@
+package history
+
+import (
+	"sort"
+	"time"
+
+	"github.com/i-am-ashwin/spydr-crawler/backend/crawler"
+	"github.com/i-am-ashwin/spydr-crawler/backend/models"
+)
+
+type Run struct {
+	ID        uint      `json:"id"`
+	URL       string    `json:"url"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type Change[T any] struct {
+	From T `json:"from"`
+	To   T `json:"to"`
+}
+
+type Link struct {
+	URL         string `json:"url"`
+	AnchorText  string `json:"anchorText"`
+	Internal    bool   `json:"internal"`
+	StatusCode  int    `json:"statusCode"`
+	StatusClass string `json:"statusClass"`
+}
+
+// Diff describes how the root page changed between two runs of the same URL.
+// Unchanged fields are left out.
+type Diff struct {
+	From              Run                    `json:"from"`
+	To                Run                    `json:"to"`
+	Changed           bool                   `json:"changed"`
+	Title             *Change[string]        `json:"title,omitempty"`
+	HTMLVersion       *Change[string]        `json:"htmlVersion,omitempty"`
+	Headings          map[string]Change[int] `json:"headings,omitempty"`
+	HasLoginForm      *Change[bool]          `json:"hasLoginForm,omitempty"`
+	LoginFormAppeared bool                   `json:"loginFormAppeared"`
+	LinksAdded        []Link                 `json:"linksAdded"`
+	LinksRemoved      []Link                 `json:"linksRemoved"`
+	NewBrokenLinks    []Link                 `json:"newBrokenLinks"`
+	FixedBrokenLinks  []Link                 `json:"fixedBrokenLinks"`
+}
+
+// Compare diffs two runs. The links are the root page links of each run.
+func Compare(from, to models.CrawlJob, fromLinks, toLinks []models.CrawlLink) Diff {
+	diff := Diff{
+		From:             Run{ID: from.ID, URL: from.URL, CreatedAt: from.CreatedAt},
+		To:               Run{ID: to.ID, URL: to.URL, CreatedAt: to.CreatedAt},
+		LinksAdded:       []Link{},
+		LinksRemoved:     []Link{},
+		NewBrokenLinks:   []Link{},
+		FixedBrokenLinks: []Link{},
+	}
+
+	if from.Title != to.Title {
+		diff.Title = &Change[string]{From: from.Title, To: to.Title}
+	}
+	if from.HTMLVersion != to.HTMLVersion {
+		diff.HTMLVersion = &Change[string]{From: from.HTMLVersion, To: to.HTMLVersion}
+	}
+	if from.HasLoginForm != to.HasLoginForm {
+		diff.HasLoginForm = &Change[bool]{From: from.HasLoginForm, To: to.HasLoginForm}
+		diff.LoginFormAppeared = to.HasLoginForm
+	}
+
+	headings := []struct {
+		name     string
+		from, to int
+	}{
+		{"h1", from.H1, to.H1},
+		{"h2", from.H2, to.H2},
+		{"h3", from.H3, to.H3},
+		{"h4", from.H4, to.H4},
+		{"h5", from.H5, to.H5},
+		{"h6", from.H6, to.H6},
+	}
+	for _, heading := range headings {
+		if heading.from == heading.to {
+			continue
+		}
+		if diff.Headings == nil {
+			diff.Headings = map[string]Change[int]{}
+		}
+		diff.Headings[heading.name] = Change[int]{From: heading.from, To: heading.to}
+	}
+
+	before := linksByURL(fromLinks)
+	after := linksByURL(toLinks)
+
+	for key, link := range after {
+		previous, existed := before[key]
+		if !existed {
+			diff.LinksAdded = append(diff.LinksAdded, link)
+		}
+		if crawler.IsBrokenClass(link.StatusClass) && (!existed || !crawler.IsBrokenClass(previous.StatusClass)) {
+			diff.NewBrokenLinks = append(diff.NewBrokenLinks, link)
+		}
+	}
+	for key, link := range before {
+		current, exists := after[key]
+		if !exists {
+			diff.LinksRemoved = append(diff.LinksRemoved, link)
+			continue
+		}
+		if crawler.IsBrokenClass(link.StatusClass) && !crawler.IsBrokenClass(current.StatusClass) {
+			diff.FixedBrokenLinks = append(diff.FixedBrokenLinks, current)
+		}
+	}
+
+	for _, links := range [][]Link{diff.LinksAdded, diff.LinksRemoved, diff.NewBrokenLinks, diff.FixedBrokenLinks} {
+		sort.Slice(links, func(i, j int) bool { return links[i].URL < links[j].URL })
+	}
+
+	diff.Changed = diff.Title != nil || diff.HTMLVersion != nil || diff.HasLoginForm != nil ||
+		len(diff.Headings) > 0 || len(diff.LinksAdded) > 0 || len(diff.LinksRemoved) > 0 ||
+		len(diff.NewBrokenLinks) > 0 || len(diff.FixedBrokenLinks) > 0
+
+	return diff
+}
+
+func linksByURL(crawlLinks []models.CrawlLink) map[string]Link {
+	links := make(map[string]Link, len(crawlLinks))
+	for _, crawlLink := range crawlLinks {
+		key := crawlLink.URL
+		if key == "" {
+			key = crawlLink.Href
+		}
+		if _, seen := links[key]; seen {
+			continue
+		}
+		links[key] = Link{
+			URL:         key,
+			AnchorText:  crawlLink.AnchorText,
+			Internal:    crawlLink.Internal,
+			StatusCode:  crawlLink.StatusCode,
+			StatusClass: crawlLink.StatusClass,
+		}
+	}
+	return links
+}
@@ -0,0 +1,166 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/i-am-ashwin/spydr-crawler/backend/crawler"
+	"github.com/i-am-ashwin/spydr-crawler/backend/models"
+	"gorm.io/gorm"
+)
+
+func validateAnalyzers(analyzers map[string]bool) error {
+	registered := make(map[string]bool)
+	for _, info := range crawler.RegisteredAnalyzers() {
+		registered[info.Name] = true
+	}
+	for name := range analyzers {
+		if !registered[name] {
+			return fmt.Errorf("unknown analyzer %q", name)
+		}
+	}
+	return nil
+}
+
+func (h *Handlers) ListAnalyzers(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, gin.H{"data": crawler.RegisteredAnalyzers()})
+}
+
+func (h *Handlers) ListFindings(ctx *gin.Context) {
+	h.listFindings(ctx, ctx.Query("analyzer"))
+}
+
+func (h *Handlers) ListSEOFindings(ctx *gin.Context) {
+	h.listFindings(ctx, crawler.AnalyzerSEO)
+}
+
+func (h *Handlers) ListAccessibilityFindings(ctx *gin.Context) {
+	h.listFindings(ctx, crawler.AnalyzerAccessibility)
+}
+
+func (h *Handlers) listFindings(ctx *gin.Context, analyzer string) {
+	var job models.CrawlJob
+	if err := h.DB.First(&job, ctx.Param("id")).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	limit, offset, ok := paginationParams(ctx)
+	if !ok {
+		return
+	}
+
+	db := h.DB.Model(&models.CrawlFinding{}).Where("job_id = ?", job.ID)
+
+	if analyzer != "" {
+		db = db.Where("analyzer IN ?", strings.Split(analyzer, ","))
+	} else {
+		db = db.Where("duplicate = ?", false)
+	}
+
+	if severity := ctx.Query("severity"); severity != "" {
+		var severities []string
+		for _, value := range strings.Split(severity, ",") {
+			if !findingSeverities[value] {
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid severity parameter"})
+				return
+			}
+			severities = append(severities, value)
+		}
+		db = db.Where("severity IN ?", severities)
+	}
+
+	if rule := ctx.Query("rule"); rule != "" {
+		db = db.Where("rule IN ?", strings.Split(rule, ","))
+	}
+
+	if pageID := ctx.Query("pageId"); pageID != "" {
+		id, err := strconv.Atoi(pageID)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid pageId parameter"})
+			return
+		}
+		db = db.Where("page_id = ?", id)
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	var findings []models.CrawlFinding
+	if err := db.Limit(limit).Offset(offset).Order("id ASC").Find(&findings).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, paginatedResponse{
+		Data:   findings,
+		Total:  total,
+		Limit:  limit,
+		Offset: offset,
+	})
+}
+
+func (h *Handlers) ListMetrics(ctx *gin.Context) {
+	var job models.CrawlJob
+	if err := h.DB.First(&job, ctx.Param("id")).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	limit, offset, ok := paginationParams(ctx)
+	if !ok {
+		return
+	}
+
+	db := h.DB.Model(&models.CrawlMetric{}).Where("job_id = ?", job.ID)
+
+	if analyzer := ctx.Query("analyzer"); analyzer != "" {
+		db = db.Where("analyzer IN ?", strings.Split(analyzer, ","))
+	}
+
+	if name := ctx.Query("name"); name != "" {
+		db = db.Where("name IN ?", strings.Split(name, ","))
+	}
+
+	if pageID := ctx.Query("pageId"); pageID != "" {
+		id, err := strconv.Atoi(pageID)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid pageId parameter"})
+			return
+		}
+		db = db.Where("page_id = ?", id)
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	var metrics []models.CrawlMetric
+	if err := db.Limit(limit).Offset(offset).Order("id ASC").Find(&metrics).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, paginatedResponse{
+		Data:   metrics,
+		Total:  total,
+		Limit:  limit,
+		Offset: offset,
+	})
+}
@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/i-am-ashwin/spydr-crawler/backend/crawler"
 	"github.com/i-am-ashwin/spydr-crawler/backend/models"
 	"github.com/i-am-ashwin/spydr-crawler/backend/worker"
 	"gorm.io/gorm"
@@ -22,7 +23,97 @@ type Handlers struct {
 }
 
 type createCrawlJobReq struct {
-	URL string `json:"url" binding:"required,url"`
+	URL               string               `json:"url" binding:"required,url,max=2048"`
+	MaxDepth          int                  `json:"maxDepth" binding:"min=0,max=5"`
+	MaxPages          int                  `json:"maxPages" binding:"min=0,max=500"`
+	IgnoreRobots      bool                 `json:"ignoreRobots"`
+	RenderMode        string               `json:"renderMode" binding:"omitempty,oneof=static rendered auto"`
+	TimeoutSeconds    int                  `json:"timeoutSeconds" binding:"min=0,max=3600"`
+	MaxAttempts       int                  `json:"maxAttempts" binding:"min=0,max=10"`
+	Priority          int                  `json:"priority" binding:"min=-100,max=100"`
+	NotBefore         *time.Time           `json:"notBefore"`
+	Screenshot        screenshotReq        `json:"screenshot"`
+	RecordHAR         bool                 `json:"recordHar"`
+	PerformanceBudget performanceBudgetReq `json:"performanceBudget"`
+	PDF               *pdfReq              `json:"pdf"`
+	Analyzers         map[string]bool      `json:"analyzers"`
+}
+
+type screenshotReq struct {
+	FullPage          bool    `json:"fullPage"`
+	Width             int     `json:"width" binding:"min=0,max=3840"`
+	Height            int     `json:"height" binding:"min=0,max=4320"`
+	Device            string  `json:"device"`
+	Mobile            bool    `json:"mobile"`
+	UserAgent         string  `json:"userAgent" binding:"max=512"`
+	DeviceScaleFactor float64 `json:"deviceScaleFactor" binding:"min=0,max=4"`
+	Format            string  `json:"format" binding:"omitempty,oneof=png jpeg webp"`
+	Quality           int     `json:"quality" binding:"min=0,max=100"`
+	WaitUntil         string  `json:"waitUntil" binding:"omitempty,oneof=load networkidle"`
+	WaitSelector      string  `json:"waitSelector" binding:"max=512"`
+	DelayMs           int     `json:"delayMs" binding:"min=0,max=30000"`
+}
+
+func (req screenshotReq) options() (models.ScreenshotOptions, error) {
+	if _, ok := crawler.Devices[req.Device]; req.Device != "" && !ok {
+		return models.ScreenshotOptions{}, fmt.Errorf("unknown device %q", req.Device)
+	}
+
+	return models.ScreenshotOptions{
+		FullPage:          req.FullPage,
+		Width:             req.Width,
+		Height:            req.Height,
+		Device:            req.Device,
+		Mobile:            req.Mobile,
+		UserAgent:         req.UserAgent,
+		DeviceScaleFactor: req.DeviceScaleFactor,
+		Format:            req.Format,
+		Quality:           req.Quality,
+		WaitUntil:         req.WaitUntil,
+		WaitSelector:      req.WaitSelector,
+		DelayMs:           req.DelayMs,
+	}, nil
+}
+
+type pdfReq struct {
+	PaperSize       string `json:"paperSize" binding:"omitempty,oneof=letter legal tabloid a3 a4 a5"`
+	Landscape       bool   `json:"landscape"`
+	PrintBackground bool   `json:"printBackground"`
+}
+
+func (req *pdfReq) options() *models.PDFOptions {
+	if req == nil {
+		return nil
+	}
+	return &models.PDFOptions{
+		PaperSize:       req.PaperSize,
+		Landscape:       req.Landscape,
+		PrintBackground: req.PrintBackground,
+	}
+}
+
+// performanceBudgetReq limits left out inherit the PERF_MAX_* defaults, and
+// limits set to zero are disabled.
+type performanceBudgetReq struct {
+	MaxTTFBMs        *float64 `json:"maxTtfbMs" binding:"omitempty,min=0"`
+	MaxLoadMs        *float64 `json:"maxLoadMs" binding:"omitempty,min=0"`
+	MaxLCPMs         *float64 `json:"maxLcpMs" binding:"omitempty,min=0"`
+	MaxCLS           *float64 `json:"maxCls" binding:"omitempty,min=0"`
+	MaxTBTMs         *float64 `json:"maxTbtMs" binding:"omitempty,min=0"`
+	MaxTransferBytes *int64   `json:"maxTransferBytes" binding:"omitempty,min=0"`
+	MaxRequests      *int     `json:"maxRequests" binding:"omitempty,min=0"`
+}
+
+func (req performanceBudgetReq) budget() models.PerformanceBudget {
+	return models.PerformanceBudget{
+		MaxTTFBMs:        req.MaxTTFBMs,
+		MaxLoadMs:        req.MaxLoadMs,
+		MaxLCPMs:         req.MaxLCPMs,
+		MaxCLS:           req.MaxCLS,
+		MaxTBTMs:         req.MaxTBTMs,
+		MaxTransferBytes: req.MaxTransferBytes,
+		MaxRequests:      req.MaxRequests,
+	}
 }
 
 type paginatedResponse struct {
@@ -39,9 +130,33 @@ func (h *Handlers) CreateCrawlJob(ctx *gin.Context) {
 		return
 	}
 
+	screenshot, err := req.Screenshot.options()
+	if err != nil {
+		ctx.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := validateAnalyzers(req.Analyzers); err != nil {
+		ctx.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
 	job := models.CrawlJob{
-		URL:    req.URL,
-		Status: models.StatusQueued,
+		URL:               req.URL,
+		Status:            models.StatusQueued,
+		MaxDepth:          req.MaxDepth,
+		MaxPages:          req.MaxPages,
+		IgnoreRobots:      req.IgnoreRobots,
+		RenderMode:        req.RenderMode,
+		TimeoutSeconds:    req.TimeoutSeconds,
+		MaxAttempts:       req.MaxAttempts,
+		Priority:          req.Priority,
+		NotBefore:         req.NotBefore,
+		Screenshot:        screenshot,
+		RecordHAR:         req.RecordHAR,
+		PerformanceBudget: req.PerformanceBudget.budget(),
+		PDF:               req.PDF.options(),
+		Analyzers:         req.Analyzers,
 	}
 
 	if err := h.DB.Create(&job).Error; err != nil {
@@ -59,6 +174,18 @@ func (h *Handlers) ListCrawlJobs(ctx *gin.Context) {
 		db = db.Where("status = ?", status)
 	}
 
+	if scheduleID := ctx.Query("scheduleId"); scheduleID != "" {
+		db = db.Where("schedule_id = ?", scheduleID)
+	}
+
+	if ctx.Query("visualRegression") == "true" {
+		db = db.Where("visual_regression = ?", true)
+	}
+
+	if ctx.Query("performanceRegression") == "true" {
+		db = db.Where("performance_regression = ?", true)
+	}
+
 	if search := ctx.Query("search"); search != "" {
 		searchPattern := "%" + search + "%"
 		db = db.Where(
@@ -67,17 +194,8 @@ func (h *Handlers) ListCrawlJobs(ctx *gin.Context) {
 		)
 	}
 
-	limitStr := ctx.DefaultQuery("limit", "50")
-	limit, err := strconv.Atoi(limitStr)
-	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit parameter"})
-		return
-	}
-
-	offsetStr := ctx.DefaultQuery("offset", "0")
-	offset, err := strconv.Atoi(offsetStr)
-	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid offset parameter"})
+	limit, offset, ok := paginationParams(ctx)
+	if !ok {
 		return
 	}
 
@@ -90,6 +208,7 @@ func (h *Handlers) ListCrawlJobs(ctx *gin.Context) {
 		"updatedAt":   "updated_at",
 		"htmlVersion": "html_version",
 		"createdAt":   "created_at",
+		"priority":    "priority",
 		"url":         "url",
 	}
 
@@ -110,7 +229,7 @@ func (h *Handlers) ListCrawlJobs(ctx *gin.Context) {
 		return
 	}
 
-	if err := db.Limit(limit).Offset(offset).Order(orderClause).Find(&jobs).Error; err != nil {
+	if err := db.Limit(limit).Offset(offset).Order(orderClause).Omit("heading_outline").Find(&jobs).Error; err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -125,9 +244,30 @@ func (h *Handlers) ListCrawlJobs(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, response)
 }
 
+func paginationParams(ctx *gin.Context) (int, int, bool) {
+	limitStr := ctx.DefaultQuery("limit", "50")
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit parameter"})
+		return 0, 0, false
+	}
+
+	offsetStr := ctx.DefaultQuery("offset", "0")
+	offset, err := strconv.Atoi(offsetStr)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid offset parameter"})
+		return 0, 0, false
+	}
+
+	return limit, offset, true
+}
+
 func (h *Handlers) GetCrawlJob(ctx *gin.Context) {
 	var job models.CrawlJob
-	if err := h.DB.First(&job, ctx.Param("id")).Error; err != nil {
+	err := h.DB.Preload("AttemptHistory", func(db *gorm.DB) *gorm.DB {
+		return db.Order("attempt ASC, id ASC")
+	}).First(&job, ctx.Param("id")).Error
+	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			ctx.JSON(http.StatusNotFound, gin.H{"error": "not found"})
 			return
@@ -138,6 +278,268 @@ func (h *Handlers) GetCrawlJob(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, job)
 }
 
+func (h *Handlers) ListCrawlPages(ctx *gin.Context) {
+	var job models.CrawlJob
+	if err := h.DB.First(&job, ctx.Param("id")).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	limit, offset, ok := paginationParams(ctx)
+	if !ok {
+		return
+	}
+
+	db := h.DB.Model(&models.CrawlPage{}).Where("job_id = ?", job.ID)
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	var pages []models.CrawlPage
+	if err := db.Limit(limit).Offset(offset).Order("depth ASC, id ASC").Find(&pages).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, paginatedResponse{
+		Data:   pages,
+		Total:  total,
+		Limit:  limit,
+		Offset: offset,
+	})
+}
+
+var linkStatusClasses = map[string]bool{
+	crawler.LinkStatusOK:          true,
+	crawler.LinkStatusRedirect:    true,
+	crawler.LinkStatusClientError: true,
+	crawler.LinkStatusServerError: true,
+	crawler.LinkStatusTimeout:     true,
+	crawler.LinkStatusDNSFailure:  true,
+	crawler.LinkStatusTLSFailure:  true,
+	crawler.LinkStatusError:       true,
+	crawler.LinkStatusBlocked:     true,
+	crawler.LinkStatusSkipped:     true,
+}
+
+var browserIssueKinds = map[string]bool{
+	crawler.IssueConsoleError:  true,
+	crawler.IssueException:     true,
+	crawler.IssueHTTPError:     true,
+	crawler.IssueRequestFailed: true,
+}
+
+func (h *Handlers) ListCrawlLinks(ctx *gin.Context) {
+	var job models.CrawlJob
+	if err := h.DB.First(&job, ctx.Param("id")).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	limit, offset, ok := paginationParams(ctx)
+	if !ok {
+		return
+	}
+
+	db := h.DB.Model(&models.CrawlLink{}).Where("job_id = ?", job.ID)
+
+	if status := ctx.Query("status"); status != "" {
+		var classes []string
+		for _, class := range strings.Split(status, ",") {
+			if !linkStatusClasses[class] {
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid status parameter"})
+				return
+			}
+			classes = append(classes, class)
+		}
+		db = db.Where("status_class IN ?", classes)
+	}
+
+	switch ctx.Query("type") {
+	case "":
+	case "internal":
+		db = db.Where("internal = ?", true)
+	case "external":
+		db = db.Where("internal = ?", false)
+	default:
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid type parameter"})
+		return
+	}
+
+	if pageID := ctx.Query("pageId"); pageID != "" {
+		id, err := strconv.Atoi(pageID)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid pageId parameter"})
+			return
+		}
+		db = db.Where("page_id = ?", id)
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	var links []models.CrawlLink
+	if err := db.Limit(limit).Offset(offset).Order("id ASC").Find(&links).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, paginatedResponse{
+		Data:   links,
+		Total:  total,
+		Limit:  limit,
+		Offset: offset,
+	})
+}
+
+func (h *Handlers) ListBrowserIssues(ctx *gin.Context) {
+	var job models.CrawlJob
+	if err := h.DB.First(&job, ctx.Param("id")).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	limit, offset, ok := paginationParams(ctx)
+	if !ok {
+		return
+	}
+
+	db := h.DB.Model(&models.CrawlBrowserIssue{}).Where("job_id = ?", job.ID)
+
+	if kind := ctx.Query("kind"); kind != "" {
+		var kinds []string
+		for _, value := range strings.Split(kind, ",") {
+			if !browserIssueKinds[value] {
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid kind parameter"})
+				return
+			}
+			kinds = append(kinds, value)
+		}
+		db = db.Where("kind IN ?", kinds)
+	}
+
+	if pageID := ctx.Query("pageId"); pageID != "" {
+		id, err := strconv.Atoi(pageID)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid pageId parameter"})
+			return
+		}
+		db = db.Where("page_id = ?", id)
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	var issues []models.CrawlBrowserIssue
+	if err := db.Limit(limit).Offset(offset).Order("id ASC").Find(&issues).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, paginatedResponse{
+		Data:   issues,
+		Total:  total,
+		Limit:  limit,
+		Offset: offset,
+	})
+}
+
+var findingSeverities = map[string]bool{
+	crawler.SeverityError:   true,
+	crawler.SeverityWarning: true,
+	crawler.SeverityInfo:    true,
+}
+
+var structuredDataFormats = map[string]bool{
+	crawler.FormatJSONLD:    true,
+	crawler.FormatMicrodata: true,
+	crawler.FormatRDFa:      true,
+}
+
+func (h *Handlers) ListStructuredData(ctx *gin.Context) {
+	var job models.CrawlJob
+	if err := h.DB.First(&job, ctx.Param("id")).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	limit, offset, ok := paginationParams(ctx)
+	if !ok {
+		return
+	}
+
+	db := h.DB.Model(&models.CrawlStructuredData{}).Where("job_id = ?", job.ID)
+
+	if format := ctx.Query("format"); format != "" {
+		if !structuredDataFormats[format] {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid format parameter"})
+			return
+		}
+		db = db.Where("format = ?", format)
+	}
+
+	if schemaType := ctx.Query("type"); schemaType != "" {
+		db = db.Where("type = ?", schemaType)
+	}
+
+	if valid := ctx.Query("valid"); valid != "" {
+		db = db.Where("valid = ?", valid == "true")
+	}
+
+	if pageID := ctx.Query("pageId"); pageID != "" {
+		id, err := strconv.Atoi(pageID)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid pageId parameter"})
+			return
+		}
+		db = db.Where("page_id = ?", id)
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	var items []models.CrawlStructuredData
+	if err := db.Limit(limit).Offset(offset).Order("id ASC").Find(&items).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, paginatedResponse{
+		Data:   items,
+		Total:  total,
+		Limit:  limit,
+		Offset: offset,
+	})
+}
+
 func (h *Handlers) CrawlJobUpdatesSSE(ctx *gin.Context) {
 	ctx.Header("Content-Type", "text/event-stream")
 	ctx.Header("Cache-Control", "no-cache")
@@ -214,25 +616,61 @@ func (h *Handlers) StopCrawlJob(ctx *gin.Context) {
 		return
 	}
 
-	if job.Status == models.StatusRunning {
-		if h.WorkerPool.CancelJob(job.ID) {
-			ctx.JSON(http.StatusOK, gin.H{"message": "Running job cancelled"})
-			return
-		}
+	stopped, err := h.stopJob(job.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if !stopped {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Job cannot be stopped"})
+		return
 	}
 
-	if job.Status == models.StatusQueued {
-		job.Status = models.StatusCanceled
+	if job.Status == models.StatusRunning {
+		ctx.JSON(http.StatusOK, gin.H{"message": "Running job cancelled"})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "Queued job cancelled"})
+}
 
-		if err := h.DB.Save(&job).Error; err != nil {
-			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-			return
+// stopJob cancels a queued or running job and releases its lease. A worker on
+// any instance that holds the job loses it at its next heartbeat; one on this
+// instance is cancelled right away. It reports false if the job had already
+// finished.
+func (h *Handlers) stopJob(id uint) (bool, error) {
+	stopped := false
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.CrawlJob{}).
+			Where("id = ? AND status IN ?", id, []models.JobStatus{models.StatusQueued, models.StatusRunning}).
+			Updates(map[string]interface{}{
+				"status":           models.StatusCanceled,
+				"error_message":    "Job was cancelled",
+				"worker_id":        "",
+				"lease_expires_at": nil,
+				"next_run_at":      nil,
+			})
+		if result.Error != nil {
+			return result.Error
 		}
-		ctx.JSON(http.StatusOK, gin.H{"message": "Queued job cancelled"})
-		return
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		stopped = true
+
+		return tx.Model(&models.CrawlAttempt{}).
+			Where("job_id = ? AND status = ?", id, models.AttemptRunning).
+			Updates(map[string]interface{}{
+				"status":        models.AttemptCanceled,
+				"error_message": "Job was cancelled",
+				"finished_at":   time.Now(),
+			}).Error
+	})
+	if err != nil || !stopped {
+		return false, err
 	}
 
-	ctx.JSON(http.StatusBadRequest, gin.H{"error": "Job cannot be stopped"})
+	h.WorkerPool.CancelJob(id)
+	return true, nil
 }
 
 func (h *Handlers) DeleteCrawlJob(ctx *gin.Context) {
@@ -291,18 +729,93 @@ func (h *Handlers) GetScreenshot(ctx *gin.Context) {
 		ctx.JSON(http.StatusNotFound, gin.H{"error": "Screenshot file not found"})
 		return
 	}
-	ctx.Header("Content-Type", "image/png")
+	ctx.Header("Content-Type", screenshotContentType(filePath))
 	ctx.Header("Cache-Control", "public, max-age=3600")
 
 	ctx.File(filePath)
 }
 
+func (h *Handlers) GetHAR(ctx *gin.Context) {
+	var job models.CrawlJob
+	if err := h.DB.First(&job, ctx.Param("id")).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if job.HARPath == "" {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "No HAR recorded for this job"})
+		return
+	}
+
+	filePath := filepath.Join(os.Getenv("SCREENSHOT_DIR"), job.HARPath)
+	if _, err := os.Stat(filePath); os.IsNotExist(err) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "HAR file not found"})
+		return
+	}
+
+	ctx.FileAttachment(filePath, fmt.Sprintf("crawl-%d.har", job.ID))
+}
+
+func (h *Handlers) GetPDF(ctx *gin.Context) {
+	var job models.CrawlJob
+	if err := h.DB.First(&job, ctx.Param("id")).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if job.PDFPath == "" {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "No PDF available for this job"})
+		return
+	}
+
+	filePath := filepath.Join(os.Getenv("SCREENSHOT_DIR"), job.PDFPath)
+	if _, err := os.Stat(filePath); os.IsNotExist(err) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "PDF file not found"})
+		return
+	}
+
+	ctx.Header("Content-Type", "application/pdf")
+	ctx.Header("Content-Disposition", fmt.Sprintf("inline; filename=\"crawl-%d.pdf\"", job.ID))
+	ctx.File(filePath)
+}
+
+func screenshotContentType(path string) string {
+	switch filepath.Ext(path) {
+	case ".jpeg", ".jpg":
+		return "image/jpeg"
+	case ".webp":
+		return "image/webp"
+	}
+	return "image/png"
+}
+
 type bulkIDsRequest struct {
 	IDs []uint `json:"ids" binding:"required,min=1"`
 }
 
 type bulkURLsRequest struct {
-	URLs []string `json:"urls" binding:"required,min=1"`
+	URLs              []string             `json:"urls" binding:"required,min=1"`
+	MaxDepth          int                  `json:"maxDepth" binding:"min=0,max=5"`
+	MaxPages          int                  `json:"maxPages" binding:"min=0,max=500"`
+	IgnoreRobots      bool                 `json:"ignoreRobots"`
+	RenderMode        string               `json:"renderMode" binding:"omitempty,oneof=static rendered auto"`
+	TimeoutSeconds    int                  `json:"timeoutSeconds" binding:"min=0,max=3600"`
+	MaxAttempts       int                  `json:"maxAttempts" binding:"min=0,max=10"`
+	Priority          int                  `json:"priority" binding:"min=-100,max=100"`
+	NotBefore         *time.Time           `json:"notBefore"`
+	Screenshot        screenshotReq        `json:"screenshot"`
+	RecordHAR         bool                 `json:"recordHar"`
+	PerformanceBudget performanceBudgetReq `json:"performanceBudget"`
+	PDF               *pdfReq              `json:"pdf"`
+	Analyzers         map[string]bool      `json:"analyzers"`
 }
 
 type bulkResponse struct {
@@ -353,13 +866,37 @@ func (h *Handlers) BulkCreateCrawlJobs(ctx *gin.Context) {
 		return
 	}
 
+	screenshot, err := req.Screenshot.options()
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := validateAnalyzers(req.Analyzers); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	var successJobs []interface{}
 	var failedURLs []interface{}
 
 	for _, url := range req.URLs {
 		job := models.CrawlJob{
-			URL:    url,
-			Status: models.StatusQueued,
+			URL:               url,
+			Status:            models.StatusQueued,
+			MaxDepth:          req.MaxDepth,
+			MaxPages:          req.MaxPages,
+			IgnoreRobots:      req.IgnoreRobots,
+			RenderMode:        req.RenderMode,
+			TimeoutSeconds:    req.TimeoutSeconds,
+			MaxAttempts:       req.MaxAttempts,
+			Priority:          req.Priority,
+			NotBefore:         req.NotBefore,
+			Screenshot:        screenshot,
+			RecordHAR:         req.RecordHAR,
+			PerformanceBudget: req.PerformanceBudget.budget(),
+			PDF:               req.PDF.options(),
+			Analyzers:         req.Analyzers,
 		}
 
 		if err := h.DB.Create(&job).Error; err != nil {
@@ -387,23 +924,8 @@ func (h *Handlers) BulkStopCrawlJobs(ctx *gin.Context) {
 	var failedIDs []interface{}
 
 	for _, id := range req.IDs {
-		var job models.CrawlJob
-		if err := h.DB.First(&job, id).Error; err != nil {
-			failedIDs = append(failedIDs, id)
-			continue
-		}
-
-		if job.Status != models.StatusRunning && job.Status != models.StatusQueued {
-			failedIDs = append(failedIDs, id)
-			continue
-		}
-
-		if job.Status == models.StatusRunning {
-			h.WorkerPool.CancelJob(id)
-		}
-
-		job.Status = models.StatusCanceled
-		if err := h.DB.Save(&job).Error; err != nil {
+		stopped, err := h.stopJob(id)
+		if err != nil || !stopped {
 			failedIDs = append(failedIDs, id)
 			continue
 		}
@@ -0,0 +1,112 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/i-am-ashwin/spydr-crawler/backend/history"
+	"github.com/i-am-ashwin/spydr-crawler/backend/models"
+	"gorm.io/gorm"
+)
+
+func (h *Handlers) ListCrawlHistory(ctx *gin.Context) {
+	var job models.CrawlJob
+	if err := h.DB.First(&job, ctx.Param("id")).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	limit, offset, ok := paginationParams(ctx)
+	if !ok {
+		return
+	}
+
+	db := h.DB.Model(&models.CrawlJob{}).Where("normalized_url = ?", job.NormalizedURL)
+	if status := ctx.Query("status"); status != "" {
+		db = db.Where("status = ?", status)
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	var jobs []models.CrawlJob
+	if err := db.Limit(limit).Offset(offset).Order("created_at DESC, id DESC").Omit("heading_outline").Find(&jobs).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, paginatedResponse{
+		Data:   jobs,
+		Total:  total,
+		Limit:  limit,
+		Offset: offset,
+	})
+}
+
+// DiffCrawlJobs compares a run with an earlier run of the same URL, given by
+// the "from" query parameter or defaulting to the previous completed run.
+func (h *Handlers) DiffCrawlJobs(ctx *gin.Context) {
+	var to models.CrawlJob
+	if err := h.DB.First(&to, ctx.Param("id")).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	var from models.CrawlJob
+	var err error
+	if fromID := ctx.Query("from"); fromID != "" {
+		err = h.DB.First(&from, fromID).Error
+	} else {
+		err = h.DB.Where("normalized_url = ? AND status = ?", to.NormalizedURL, models.StatusDone).
+			Where("created_at < ? OR (created_at = ? AND id < ?)", to.CreatedAt, to.CreatedAt, to.ID).
+			Order("created_at DESC, id DESC").
+			First(&from).Error
+	}
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "no run to compare with"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if from.NormalizedURL != to.NormalizedURL {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "runs are for different URLs"})
+		return
+	}
+	if from.Status != models.StatusDone || to.Status != models.StatusDone {
+		ctx.JSON(http.StatusConflict, gin.H{"error": "both runs must be done"})
+		return
+	}
+
+	fromLinks, err := h.rootLinks(from.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	toLinks, err := h.rootLinks(to.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, history.Compare(from, to, fromLinks, toLinks))
+}
+
+func (h *Handlers) rootLinks(jobID uint) ([]models.CrawlLink, error) {
+	var links []models.CrawlLink
+	err := h.DB.Where("job_id = ? AND page_id IS NULL", jobID).Order("id ASC").Find(&links).Error
+	return links, err
+}
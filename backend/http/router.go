@@ -31,9 +31,33 @@ func SetupRouter(db *gorm.DB, pool *worker.WorkerPool) *gin.Engine {
 		protected.POST("/crawl/:id/stop", handlers.StopCrawlJob)
 		protected.DELETE("/crawl/:id", handlers.DeleteCrawlJob)
 		protected.GET("/crawl/:id/screenshot", handlers.GetScreenshot)
+		protected.GET("/crawl/:id/screenshot/diff", handlers.GetScreenshotDiff)
+		protected.GET("/crawl/:id/har", handlers.GetHAR)
+		protected.GET("/crawl/:id/pdf", handlers.GetPDF)
+		protected.GET("/crawl/:id/visual-diff", handlers.GetVisualDiff)
+		protected.POST("/crawl/:id/baseline", handlers.PinScreenshotBaseline)
+		protected.DELETE("/crawl/:id/baseline", handlers.DeleteScreenshotBaseline)
+		protected.GET("/crawl/:id/pages", handlers.ListCrawlPages)
+		protected.GET("/crawl/:id/links", handlers.ListCrawlLinks)
+		protected.GET("/crawl/:id/browser-issues", handlers.ListBrowserIssues)
+		protected.GET("/crawl/:id/seo-findings", handlers.ListSEOFindings)
+		protected.GET("/crawl/:id/structured-data", handlers.ListStructuredData)
+		protected.GET("/crawl/:id/accessibility", handlers.ListAccessibilityFindings)
+		protected.GET("/crawl/:id/findings", handlers.ListFindings)
+		protected.GET("/crawl/:id/metrics", handlers.ListMetrics)
+		protected.GET("/crawl/:id/history", handlers.ListCrawlHistory)
+		protected.GET("/crawl/:id/diff", handlers.DiffCrawlJobs)
 		protected.POST("/crawl/bulk/create", handlers.BulkCreateCrawlJobs)
 		protected.POST("/crawl/bulk/delete", handlers.BulkDeleteCrawlJobs)
 		protected.POST("/crawl/bulk/stop", handlers.BulkStopCrawlJobs)
+
+		protected.GET("/analyzers", handlers.ListAnalyzers)
+
+		protected.POST("/schedules", handlers.CreateSchedule)
+		protected.GET("/schedules", handlers.ListSchedules)
+		protected.GET("/schedules/:id", handlers.GetSchedule)
+		protected.PUT("/schedules/:id", handlers.UpdateSchedule)
+		protected.DELETE("/schedules/:id", handlers.DeleteSchedule)
 	}
 
 	return r
@@ -0,0 +1,186 @@
+package http
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/i-am-ashwin/spydr-crawler/backend/models"
+	"github.com/i-am-ashwin/spydr-crawler/backend/scheduler"
+	"gorm.io/gorm"
+)
+
+type scheduleReq struct {
+	Name              string                 `json:"name" binding:"max=255"`
+	CronExpression    string                 `json:"cronExpression"`
+	IntervalSeconds   int                    `json:"intervalSeconds" binding:"min=0"`
+	URLs              []string               `json:"urls" binding:"required,min=1,max=100,dive,url,max=2048"`
+	MaxDepth          int                    `json:"maxDepth" binding:"min=0,max=5"`
+	MaxPages          int                    `json:"maxPages" binding:"min=0,max=500"`
+	IgnoreRobots      bool                   `json:"ignoreRobots"`
+	RenderMode        string                 `json:"renderMode" binding:"omitempty,oneof=static rendered auto"`
+	TimeoutSeconds    int                    `json:"timeoutSeconds" binding:"min=0,max=3600"`
+	MaxAttempts       int                    `json:"maxAttempts" binding:"min=0,max=10"`
+	Priority          int                    `json:"priority" binding:"min=-100,max=100"`
+	MissedRunPolicy   models.MissedRunPolicy `json:"missedRunPolicy"`
+	Screenshot        screenshotReq          `json:"screenshot"`
+	RecordHAR         bool                   `json:"recordHar"`
+	PerformanceBudget performanceBudgetReq   `json:"performanceBudget"`
+	PDF               *pdfReq                `json:"pdf"`
+	Analyzers         map[string]bool        `json:"analyzers"`
+	Enabled           *bool                  `json:"enabled"`
+}
+
+func (req scheduleReq) apply(schedule *models.CrawlSchedule) error {
+	schedule.Name = req.Name
+	schedule.CronExpression = req.CronExpression
+	schedule.IntervalSeconds = req.IntervalSeconds
+	schedule.URLs = req.URLs
+	schedule.MaxDepth = req.MaxDepth
+	schedule.MaxPages = req.MaxPages
+	schedule.IgnoreRobots = req.IgnoreRobots
+	schedule.RenderMode = req.RenderMode
+	schedule.TimeoutSeconds = req.TimeoutSeconds
+	schedule.MaxAttempts = req.MaxAttempts
+	schedule.Priority = req.Priority
+	schedule.MissedRunPolicy = req.MissedRunPolicy
+	if schedule.MissedRunPolicy == "" {
+		schedule.MissedRunPolicy = models.MissedRunOnce
+	}
+	schedule.Enabled = req.Enabled == nil || *req.Enabled
+
+	screenshot, err := req.Screenshot.options()
+	if err != nil {
+		return err
+	}
+	schedule.Screenshot = screenshot
+	schedule.RecordHAR = req.RecordHAR
+	schedule.PerformanceBudget = req.PerformanceBudget.budget()
+	schedule.PDF = req.PDF.options()
+
+	if err := validateAnalyzers(req.Analyzers); err != nil {
+		return err
+	}
+	schedule.Analyzers = req.Analyzers
+
+	if err := scheduler.Validate(*schedule); err != nil {
+		return err
+	}
+
+	// Timing changes restart the schedule from now.
+	schedule.NextRunAt = time.Time{}
+	next, err := scheduler.NextRun(*schedule, time.Now())
+	if err != nil {
+		return err
+	}
+	schedule.NextRunAt = next
+	return nil
+}
+
+func (h *Handlers) CreateSchedule(ctx *gin.Context) {
+	var req scheduleReq
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var schedule models.CrawlSchedule
+	if err := req.apply(&schedule); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.DB.Create(&schedule).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create schedule"})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, schedule)
+}
+
+func (h *Handlers) ListSchedules(ctx *gin.Context) {
+	limit, offset, ok := paginationParams(ctx)
+	if !ok {
+		return
+	}
+
+	db := h.DB.Model(&models.CrawlSchedule{})
+	if enabled := ctx.Query("enabled"); enabled != "" {
+		db = db.Where("enabled = ?", enabled == "true")
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	var schedules []models.CrawlSchedule
+	if err := db.Limit(limit).Offset(offset).Order("created_at DESC").Find(&schedules).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, paginatedResponse{
+		Data:   schedules,
+		Total:  total,
+		Limit:  limit,
+		Offset: offset,
+	})
+}
+
+func (h *Handlers) GetSchedule(ctx *gin.Context) {
+	var schedule models.CrawlSchedule
+	if err := h.DB.First(&schedule, ctx.Param("id")).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, schedule)
+}
+
+func (h *Handlers) UpdateSchedule(ctx *gin.Context) {
+	var schedule models.CrawlSchedule
+	if err := h.DB.First(&schedule, ctx.Param("id")).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req scheduleReq
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := req.apply(&schedule); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.DB.Save(&schedule).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update schedule"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, schedule)
+}
+
+func (h *Handlers) DeleteSchedule(ctx *gin.Context) {
+	result := h.DB.Delete(&models.CrawlSchedule{}, ctx.Param("id"))
+	if result.Error != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": result.Error.Error()})
+		return
+	}
+	if result.RowsAffected == 0 {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Schedule deleted successfully"})
+}
@@ -0,0 +1,186 @@
+package http
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/i-am-ashwin/spydr-crawler/backend/models"
+	"github.com/i-am-ashwin/spydr-crawler/backend/visual"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// GetVisualDiff compares a run's screenshot with the "from" run, or by default
+// with the pinned baseline or previous run, using an optional "threshold" percentage.
+func (h *Handlers) GetVisualDiff(ctx *gin.Context) {
+	threshold := visual.DefaultThreshold()
+	if value := ctx.Query("threshold"); value != "" {
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil || parsed < 0 || parsed > 100 {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid threshold parameter"})
+			return
+		}
+		threshold = parsed
+	}
+
+	to, from, ok := h.visualDiffRuns(ctx)
+	if !ok {
+		return
+	}
+
+	result, err := visual.CompareJobs(from, to, threshold, nil)
+	if err != nil {
+		visualDiffError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+func visualDiffError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, visual.ErrNoScreenshot) || os.IsNotExist(err):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Screenshot file not found"})
+	case errors.Is(err, visual.ErrImageTooLarge):
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	default:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// GetScreenshotDiff serves the diff image stored when the run completed, or
+// with "from" renders one against that run without storing it.
+func (h *Handlers) GetScreenshotDiff(ctx *gin.Context) {
+	if ctx.Query("from") != "" {
+		to, from, ok := h.visualDiffRuns(ctx)
+		if !ok {
+			return
+		}
+		var diff bytes.Buffer
+		if _, err := visual.CompareJobs(from, to, visual.DefaultThreshold(), &diff); err != nil {
+			visualDiffError(ctx, err)
+			return
+		}
+		ctx.Header("Cache-Control", "no-cache")
+		ctx.Data(http.StatusOK, "image/png", diff.Bytes())
+		return
+	}
+
+	var job models.CrawlJob
+	if err := h.DB.First(&job, ctx.Param("id")).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	diffPath := job.VisualDiffPath
+
+	if diffPath == "" {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "No screenshot diff available for this job"})
+		return
+	}
+
+	filePath := filepath.Join(os.Getenv("SCREENSHOT_DIR"), diffPath)
+	if _, err := os.Stat(filePath); os.IsNotExist(err) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Screenshot diff file not found"})
+		return
+	}
+	ctx.Header("Content-Type", "image/png")
+	ctx.Header("Cache-Control", "no-cache")
+
+	ctx.File(filePath)
+}
+
+func (h *Handlers) visualDiffRuns(ctx *gin.Context) (models.CrawlJob, models.CrawlJob, bool) {
+	var to, from models.CrawlJob
+	if err := h.DB.First(&to, ctx.Param("id")).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+			return to, from, false
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return to, from, false
+	}
+
+	var err error
+	if fromID := ctx.Query("from"); fromID != "" {
+		err = h.DB.First(&from, fromID).Error
+	} else {
+		from, err = visual.Reference(h.DB, to)
+	}
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "no run to compare with"})
+			return to, from, false
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return to, from, false
+	}
+
+	if from.NormalizedURL != to.NormalizedURL {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "runs are for different URLs"})
+		return to, from, false
+	}
+
+	return to, from, true
+}
+
+func (h *Handlers) PinScreenshotBaseline(ctx *gin.Context) {
+	var job models.CrawlJob
+	if err := h.DB.First(&job, ctx.Param("id")).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if job.Status != models.StatusDone || job.ScreenshotPath == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Only completed jobs with a screenshot can be a baseline"})
+		return
+	}
+
+	baseline := models.ScreenshotBaseline{NormalizedURL: job.NormalizedURL, JobID: job.ID}
+	err := h.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "normalized_url"}},
+		DoUpdates: clause.AssignmentColumns([]string{"job_id", "updated_at"}),
+	}).Create(&baseline).Error
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, baseline)
+}
+
+func (h *Handlers) DeleteScreenshotBaseline(ctx *gin.Context) {
+	var job models.CrawlJob
+	if err := h.DB.First(&job, ctx.Param("id")).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	result := h.DB.Where("normalized_url = ?", job.NormalizedURL).Delete(&models.ScreenshotBaseline{})
+	if result.Error != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": result.Error.Error()})
+		return
+	}
+	if result.RowsAffected == 0 {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "No baseline pinned for this URL"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Baseline removed successfully"})
+}
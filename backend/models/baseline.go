@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// ScreenshotBaseline pins the run that later runs of a URL are visually compared with.
+type ScreenshotBaseline struct {
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	NormalizedURL string    `gorm:"size:768;uniqueIndex" json:"normalizedUrl"`
+	JobID         uint      `gorm:"index;not null" json:"jobId"`
+	CreatedAt     time.Time `json:"createdAt"`
+	UpdatedAt     time.Time `json:"updatedAt"`
+}
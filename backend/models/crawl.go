@@ -1,6 +1,8 @@
 package models
 
 import (
+	"net/url"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
@@ -17,24 +19,218 @@ const (
 )
 
 type CrawlJob struct {
-	ID                uint           `gorm:"primaryKey" json:"id"`
-	URL               string         `gorm:"size:2048;not null" json:"url"`
-	Title             string         `json:"title"`
-	HTMLVersion       string         `json:"htmlVersion"`
-	H1                int            `json:"h1"`
-	H2                int            `json:"h2"`
-	H3                int            `json:"h3"`
-	H4                int            `json:"h4"`
-	H5                int            `json:"h5"`
-	H6                int            `json:"h6"`
-	InternalLinks     int            `json:"internalLinks"`
-	ExternalLinks     int            `json:"externalLinks"`
-	InaccessibleLinks int            `json:"inaccessibleLinks"`
-	HasLoginForm      bool           `json:"hasLoginForm"`
-	ScreenshotPath    string         `json:"screenshotPath"`
-	Status            JobStatus      `gorm:"type:enum('queued','running','done','error','canceled');default:'queued'" json:"status"`
-	ErrorMessage      string         `json:"errorMessage"`
-	CreatedAt         time.Time      `json:"createdAt"`
-	UpdatedAt         time.Time      `json:"updatedAt"`
-	DeletedAt         gorm.DeletedAt `gorm:"index" json:"-"`
+	ID                    uint                `gorm:"primaryKey" json:"id"`
+	URL                   string              `gorm:"size:2048;not null" json:"url"`
+	NormalizedURL         string              `gorm:"size:768;index" json:"normalizedUrl"`
+	Title                 string              `json:"title"`
+	HTMLVersion           string              `json:"htmlVersion"`
+	H1                    int                 `json:"h1"`
+	H2                    int                 `json:"h2"`
+	H3                    int                 `json:"h3"`
+	H4                    int                 `json:"h4"`
+	H5                    int                 `json:"h5"`
+	H6                    int                 `json:"h6"`
+	HeadingOutline        *HeadingOutline     `gorm:"type:mediumtext;serializer:json" json:"headingOutline,omitempty"`
+	OutlineIssues         int                 `json:"outlineIssues"`
+	InternalLinks         int                 `json:"internalLinks"`
+	ExternalLinks         int                 `json:"externalLinks"`
+	InaccessibleLinks     int                 `json:"inaccessibleLinks"`
+	HasLoginForm          bool                `json:"hasLoginForm"`
+	ConsoleErrors         int                 `json:"consoleErrors"`
+	FailedRequests        int                 `json:"failedRequests"`
+	SEO                   *SEOMetadata        `gorm:"type:mediumtext;serializer:json" json:"seo"`
+	SEOFindings           int                 `json:"seoFindings"`
+	StructuredDataItems   int                 `json:"structuredDataItems"`
+	StructuredDataErrors  int                 `json:"structuredDataErrors"`
+	AccessibilityFindings int                 `json:"accessibilityFindings"`
+	Findings              int                 `json:"findings"`
+	ScreenshotPath        string              `json:"screenshotPath"`
+	Screenshot            ScreenshotOptions   `gorm:"type:text;serializer:json" json:"screenshot"`
+	RecordHAR             bool                `json:"recordHar"`
+	HARPath               string              `json:"harPath"`
+	PDF                   *PDFOptions         `gorm:"type:text;serializer:json" json:"pdf"`
+	PDFPath               string              `json:"pdfPath"`
+	VisualDiffPath        string              `json:"visualDiffPath"`
+	VisualDiffPercent     *float64            `json:"visualDiffPercent"`
+	VisualDiffAgainst     *uint               `json:"visualDiffAgainst"`
+	VisualRegression      bool                `gorm:"index" json:"visualRegression"`
+	Performance           *PerformanceMetrics `gorm:"type:text;serializer:json" json:"performance"`
+	PerformanceBudget     PerformanceBudget   `gorm:"type:text;serializer:json" json:"performanceBudget"`
+	PerformanceViolations []string            `gorm:"type:text;serializer:json" json:"performanceViolations"`
+	PerformanceRegression bool                `gorm:"index" json:"performanceRegression"`
+	Analyzers             map[string]bool     `gorm:"type:text;serializer:json" json:"analyzers"`
+	MaxDepth              int                 `json:"maxDepth"`
+	MaxPages              int                 `json:"maxPages"`
+	PagesCrawled          int                 `json:"pagesCrawled"`
+	IgnoreRobots          bool                `json:"ignoreRobots"`
+	RenderMode            string              `gorm:"size:16" json:"renderMode"`
+	RenderedWith          string              `gorm:"size:16" json:"renderedWith"`
+	TimeoutSeconds        int                 `json:"timeoutSeconds"`
+	WorkerID              string              `gorm:"size:255;index" json:"workerId"`
+	Attempts              int                 `json:"attempts"`
+	MaxAttempts           int                 `json:"maxAttempts"`
+	NextRunAt             *time.Time          `gorm:"index" json:"nextRunAt"`
+	Priority              int                 `gorm:"default:0;index:idx_crawl_jobs_claim,priority:2,sort:desc" json:"priority"`
+	NotBefore             *time.Time          `json:"notBefore"`
+	ScheduleID            *uint               `gorm:"index" json:"scheduleId"`
+	HeartbeatAt           *time.Time          `json:"heartbeatAt"`
+	LeaseExpiresAt        *time.Time          `gorm:"index" json:"leaseExpiresAt"`
+	Status                JobStatus           `gorm:"type:enum('queued','running','done','error','canceled');default:'queued';index:idx_crawl_jobs_claim,priority:1" json:"status"`
+	ErrorMessage          string              `json:"errorMessage"`
+	CreatedAt             time.Time           `gorm:"index:idx_crawl_jobs_claim,priority:3" json:"createdAt"`
+	UpdatedAt             time.Time           `json:"updatedAt"`
+	DeletedAt             gorm.DeletedAt      `gorm:"index" json:"-"`
+	AttemptHistory        []CrawlAttempt      `gorm:"foreignKey:JobID" json:"attemptHistory,omitempty"`
+}
+
+func (job *CrawlJob) BeforeCreate(tx *gorm.DB) error {
+	job.NormalizedURL = NormalizeURL(job.URL)
+	return nil
+}
+
+// NormalizeURL returns the key that groups runs of the same page into a
+// history: scheme and host are lowercased, default ports, fragments and
+// trailing slashes are dropped.
+func NormalizeURL(raw string) string {
+	parsedUrl, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || parsedUrl.Host == "" {
+		return truncateURL(strings.TrimSpace(raw))
+	}
+
+	parsedUrl.Scheme = strings.ToLower(parsedUrl.Scheme)
+	host := strings.ToLower(parsedUrl.Hostname())
+	port := parsedUrl.Port()
+	if port != "" && !(parsedUrl.Scheme == "http" && port == "80") && !(parsedUrl.Scheme == "https" && port == "443") {
+		host += ":" + port
+	}
+	parsedUrl.Host = host
+	parsedUrl.Fragment = ""
+	parsedUrl.RawFragment = ""
+	parsedUrl.Path = strings.TrimRight(parsedUrl.Path, "/")
+	parsedUrl.RawPath = ""
+	if parsedUrl.Path == "" {
+		parsedUrl.Path = "/"
+	}
+
+	return truncateURL(parsedUrl.String())
+}
+
+func truncateURL(value string) string {
+	if len(value) > 768 {
+		return value[:768]
+	}
+	return value
+}
+
+type AttemptStatus string
+
+const (
+	AttemptRunning      AttemptStatus = "running"
+	AttemptSucceeded    AttemptStatus = "succeeded"
+	AttemptFailed       AttemptStatus = "failed"
+	AttemptRetrying     AttemptStatus = "retrying"
+	AttemptCanceled     AttemptStatus = "canceled"
+	AttemptRequeued     AttemptStatus = "requeued"
+	AttemptLeaseExpired AttemptStatus = "lease_expired"
+)
+
+type CrawlAttempt struct {
+	ID           uint          `gorm:"primaryKey" json:"id"`
+	JobID        uint          `gorm:"index;not null" json:"jobId"`
+	Attempt      int           `json:"attempt"`
+	WorkerID     string        `gorm:"size:255" json:"workerId"`
+	Status       AttemptStatus `gorm:"size:32;index" json:"status"`
+	Transient    bool          `json:"transient"`
+	ErrorMessage string        `json:"errorMessage"`
+	StartedAt    time.Time     `json:"startedAt"`
+	FinishedAt   *time.Time    `json:"finishedAt"`
+}
+
+type CrawlPage struct {
+	ID                    uint            `gorm:"primaryKey" json:"id"`
+	JobID                 uint            `gorm:"index;not null" json:"jobId"`
+	URL                   string          `gorm:"size:2048;not null" json:"url"`
+	Depth                 int             `json:"depth"`
+	Title                 string          `json:"title"`
+	HTMLVersion           string          `json:"htmlVersion"`
+	H1                    int             `json:"h1"`
+	H2                    int             `json:"h2"`
+	H3                    int             `json:"h3"`
+	H4                    int             `json:"h4"`
+	H5                    int             `json:"h5"`
+	H6                    int             `json:"h6"`
+	HeadingOutline        *HeadingOutline `gorm:"type:mediumtext;serializer:json" json:"headingOutline,omitempty"`
+	OutlineIssues         int             `json:"outlineIssues"`
+	InternalLinks         int             `json:"internalLinks"`
+	ExternalLinks         int             `json:"externalLinks"`
+	InaccessibleLinks     int             `json:"inaccessibleLinks"`
+	HasLoginForm          bool            `json:"hasLoginForm"`
+	RenderedWith          string          `gorm:"size:16" json:"renderedWith"`
+	ConsoleErrors         int             `json:"consoleErrors"`
+	FailedRequests        int             `json:"failedRequests"`
+	SEO                   *SEOMetadata    `gorm:"type:mediumtext;serializer:json" json:"seo"`
+	SEOFindings           int             `json:"seoFindings"`
+	StructuredDataItems   int             `json:"structuredDataItems"`
+	StructuredDataErrors  int             `json:"structuredDataErrors"`
+	AccessibilityFindings int             `json:"accessibilityFindings"`
+	Findings              int             `json:"findings"`
+	ErrorMessage          string          `json:"errorMessage"`
+	CreatedAt             time.Time       `json:"createdAt"`
+}
+
+type CrawlLink struct {
+	ID            uint          `gorm:"primaryKey" json:"id"`
+	JobID         uint          `gorm:"index;not null" json:"jobId"`
+	PageID        *uint         `gorm:"index" json:"pageId"`
+	Href          string        `gorm:"size:2048" json:"href"`
+	URL           string        `gorm:"size:2048" json:"url"`
+	AnchorText    string        `gorm:"size:1024" json:"anchorText"`
+	Rel           string        `json:"rel"`
+	Internal      bool          `json:"internal"`
+	StatusCode    int           `json:"statusCode"`
+	StatusClass   string        `gorm:"size:32;index" json:"statusClass"`
+	RedirectChain []RedirectHop `gorm:"type:text;serializer:json" json:"redirectChain"`
+	ErrorMessage  string        `json:"errorMessage"`
+	CreatedAt     time.Time     `json:"createdAt"`
+}
+
+type ScreenshotOptions struct {
+	FullPage          bool    `json:"fullPage"`
+	Width             int     `json:"width,omitempty"`
+	Height            int     `json:"height,omitempty"`
+	Device            string  `json:"device,omitempty"`
+	Mobile            bool    `json:"mobile,omitempty"`
+	UserAgent         string  `json:"userAgent,omitempty"`
+	DeviceScaleFactor float64 `json:"deviceScaleFactor,omitempty"`
+	Format            string  `json:"format,omitempty"`
+	Quality           int     `json:"quality,omitempty"`
+	WaitUntil         string  `json:"waitUntil,omitempty"`
+	WaitSelector      string  `json:"waitSelector,omitempty"`
+	DelayMs           int     `json:"delayMs,omitempty"`
+}
+
+// PDFOptions asks for a print-to-PDF copy of the root page.
+type PDFOptions struct {
+	PaperSize       string `json:"paperSize"`
+	Landscape       bool   `json:"landscape"`
+	PrintBackground bool   `json:"printBackground"`
+}
+
+type CrawlBrowserIssue struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	JobID        uint      `gorm:"index;not null" json:"jobId"`
+	PageID       *uint     `gorm:"index" json:"pageId"`
+	Kind         string    `gorm:"size:32;index" json:"kind"`
+	Message      string    `gorm:"type:text" json:"message"`
+	URL          string    `gorm:"size:2048" json:"url"`
+	StatusCode   int       `json:"statusCode"`
+	ResourceType string    `gorm:"size:32" json:"resourceType"`
+	Line         int       `json:"line"`
+	Column       int       `json:"column"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+type RedirectHop struct {
+	URL        string `json:"url"`
+	StatusCode int    `json:"statusCode"`
 }
@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// CrawlFinding is a problem an analyzer reported on a page, such as a missing
+// canonical URL or an image without alt text.
+//
+// Duplicate is set when another analyzer already reported the same rule and
+// message on the page; duplicates are left out of the combined findings list.
+type CrawlFinding struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	JobID     uint      `gorm:"index;not null" json:"jobId"`
+	PageID    *uint     `gorm:"index" json:"pageId"`
+	Analyzer  string    `gorm:"size:64;index" json:"analyzer"`
+	Rule      string    `gorm:"size:64;index" json:"rule"`
+	Severity  string    `gorm:"size:16;index" json:"severity"`
+	Message   string    `gorm:"type:text" json:"message"`
+	Selector  string    `gorm:"size:1024" json:"selector"`
+	Duplicate bool      `gorm:"index;not null;default:false" json:"duplicate"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// CrawlMetric is a named value an analyzer measured on a page.
+type CrawlMetric struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	JobID     uint      `gorm:"index;not null" json:"jobId"`
+	PageID    *uint     `gorm:"index" json:"pageId"`
+	Analyzer  string    `gorm:"size:64;index" json:"analyzer"`
+	Name      string    `gorm:"size:64;index" json:"name"`
+	Value     float64   `json:"value"`
+	CreatedAt time.Time `json:"createdAt"`
+}
@@ -0,0 +1,17 @@
+package models
+
+type Heading struct {
+	Level    int        `json:"level"`
+	Text     string     `json:"text"`
+	Children []*Heading `json:"children,omitempty"`
+}
+
+type OutlineIssue struct {
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+type HeadingOutline struct {
+	Headings []*Heading     `json:"headings"`
+	Issues   []OutlineIssue `json:"issues"`
+}
@@ -0,0 +1,82 @@
+package models
+
+import "fmt"
+
+// PerformanceMetrics are the page load measurements of a rendered root page.
+// Timings are in milliseconds; metrics the browser could not measure are nil.
+type PerformanceMetrics struct {
+	TTFB             *float64       `json:"ttfb"`
+	DOMContentLoaded *float64       `json:"domContentLoaded"`
+	Load             *float64       `json:"load"`
+	LCP              *float64       `json:"lcp"`
+	CLS              *float64       `json:"cls"`
+	TBT              *float64       `json:"tbt"`
+	TransferBytes    int64          `json:"transferBytes"`
+	Requests         int            `json:"requests"`
+	RequestsByType   map[string]int `json:"requestsByType"`
+}
+
+// PerformanceBudget holds the limits past which a run is flagged as a
+// performance regression. A nil limit is inherited from the defaults and a
+// zero limit is disabled.
+type PerformanceBudget struct {
+	MaxTTFBMs        *float64 `json:"maxTtfbMs"`
+	MaxLoadMs        *float64 `json:"maxLoadMs"`
+	MaxLCPMs         *float64 `json:"maxLcpMs"`
+	MaxCLS           *float64 `json:"maxCls"`
+	MaxTBTMs         *float64 `json:"maxTbtMs"`
+	MaxTransferBytes *int64   `json:"maxTransferBytes"`
+	MaxRequests      *int     `json:"maxRequests"`
+}
+
+// Or fills the limits not set on budget from defaults.
+func (budget PerformanceBudget) Or(defaults PerformanceBudget) PerformanceBudget {
+	budget.MaxTTFBMs = or(budget.MaxTTFBMs, defaults.MaxTTFBMs)
+	budget.MaxLoadMs = or(budget.MaxLoadMs, defaults.MaxLoadMs)
+	budget.MaxLCPMs = or(budget.MaxLCPMs, defaults.MaxLCPMs)
+	budget.MaxCLS = or(budget.MaxCLS, defaults.MaxCLS)
+	budget.MaxTBTMs = or(budget.MaxTBTMs, defaults.MaxTBTMs)
+	budget.MaxTransferBytes = or(budget.MaxTransferBytes, defaults.MaxTransferBytes)
+	budget.MaxRequests = or(budget.MaxRequests, defaults.MaxRequests)
+	return budget
+}
+
+func or[T any](value, fallback *T) *T {
+	if value != nil {
+		return value
+	}
+	return fallback
+}
+
+// limit returns the value of a limit, or false when it is unset or disabled.
+func limit[T int | int64 | float64](value *T) (T, bool) {
+	if value == nil || *value <= 0 {
+		return 0, false
+	}
+	return *value, true
+}
+
+// Violations lists every metric that is over its limit.
+func (budget PerformanceBudget) Violations(metrics PerformanceMetrics) []string {
+	var violations []string
+	timing := func(name string, value *float64, max *float64) {
+		if allowed, ok := limit(max); ok && value != nil && *value > allowed {
+			violations = append(violations, fmt.Sprintf("%s %.0fms exceeds %.0fms", name, *value, allowed))
+		}
+	}
+
+	timing("ttfb", metrics.TTFB, budget.MaxTTFBMs)
+	timing("load", metrics.Load, budget.MaxLoadMs)
+	timing("lcp", metrics.LCP, budget.MaxLCPMs)
+	timing("tbt", metrics.TBT, budget.MaxTBTMs)
+	if allowed, ok := limit(budget.MaxCLS); ok && metrics.CLS != nil && *metrics.CLS > allowed {
+		violations = append(violations, fmt.Sprintf("cls %.3f exceeds %.3f", *metrics.CLS, allowed))
+	}
+	if allowed, ok := limit(budget.MaxTransferBytes); ok && metrics.TransferBytes > allowed {
+		violations = append(violations, fmt.Sprintf("transfer size %d bytes exceeds %d bytes", metrics.TransferBytes, allowed))
+	}
+	if allowed, ok := limit(budget.MaxRequests); ok && metrics.Requests > allowed {
+		violations = append(violations, fmt.Sprintf("%d requests exceeds %d", metrics.Requests, allowed))
+	}
+	return violations
+}
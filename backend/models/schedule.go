@@ -0,0 +1,43 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type MissedRunPolicy string
+
+const (
+	// MissedRunOnce enqueues a single catch-up run for any number of missed runs.
+	MissedRunOnce MissedRunPolicy = "run_once"
+	// MissedRunSkip drops missed runs and waits for the next scheduled time.
+	MissedRunSkip MissedRunPolicy = "skip"
+)
+
+type CrawlSchedule struct {
+	ID                uint              `gorm:"primaryKey" json:"id"`
+	Name              string            `gorm:"size:255" json:"name"`
+	CronExpression    string            `gorm:"size:255" json:"cronExpression"`
+	IntervalSeconds   int               `json:"intervalSeconds"`
+	URLs              []string          `gorm:"type:mediumtext;serializer:json" json:"urls"`
+	MaxDepth          int               `json:"maxDepth"`
+	MaxPages          int               `json:"maxPages"`
+	IgnoreRobots      bool              `json:"ignoreRobots"`
+	RenderMode        string            `gorm:"size:16" json:"renderMode"`
+	TimeoutSeconds    int               `json:"timeoutSeconds"`
+	MaxAttempts       int               `json:"maxAttempts"`
+	Priority          int               `json:"priority"`
+	Screenshot        ScreenshotOptions `gorm:"type:text;serializer:json" json:"screenshot"`
+	RecordHAR         bool              `json:"recordHar"`
+	PDF               *PDFOptions       `gorm:"type:text;serializer:json" json:"pdf"`
+	PerformanceBudget PerformanceBudget `gorm:"type:text;serializer:json" json:"performanceBudget"`
+	Analyzers         map[string]bool   `gorm:"type:text;serializer:json" json:"analyzers"`
+	MissedRunPolicy   MissedRunPolicy   `gorm:"size:32;default:'run_once'" json:"missedRunPolicy"`
+	Enabled           bool              `gorm:"index:idx_crawl_schedules_due,priority:1" json:"enabled"`
+	NextRunAt         time.Time         `gorm:"index:idx_crawl_schedules_due,priority:2" json:"nextRunAt"`
+	LastRunAt         *time.Time        `json:"lastRunAt"`
+	CreatedAt         time.Time         `json:"createdAt"`
+	UpdatedAt         time.Time         `json:"updatedAt"`
+	DeletedAt         gorm.DeletedAt    `gorm:"index" json:"-"`
+}
@@ -0,0 +1,15 @@
+package models
+
+type SEOMetadata struct {
+	Lang             string            `json:"lang"`
+	Description      string            `json:"description"`
+	DescriptionCount int               `json:"descriptionCount"`
+	Robots           string            `json:"robots"`
+	XRobotsTag       string            `json:"xRobotsTag"`
+	Canonical        string            `json:"canonical"`
+	CanonicalCount   int               `json:"canonicalCount"`
+	Viewport         string            `json:"viewport"`
+	Favicon          string            `json:"favicon"`
+	OpenGraph        map[string]string `json:"openGraph"`
+	TwitterCard      map[string]string `json:"twitterCard"`
+}
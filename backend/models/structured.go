@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// CrawlStructuredData is one JSON-LD, microdata or RDFa item found on a page,
+// normalized to a JSON-LD style object.
+type CrawlStructuredData struct {
+	ID        uint                   `gorm:"primaryKey" json:"id"`
+	JobID     uint                   `gorm:"index;not null" json:"jobId"`
+	PageID    *uint                  `gorm:"index" json:"pageId"`
+	Format    string                 `gorm:"size:16;index" json:"format"`
+	Type      string                 `gorm:"size:255;index" json:"type"`
+	Data      map[string]interface{} `gorm:"type:mediumtext;serializer:json" json:"data"`
+	Errors    []string               `gorm:"type:text;serializer:json" json:"errors"`
+	Valid     bool                   `gorm:"index" json:"valid"`
+	CreatedAt time.Time              `json:"createdAt"`
+}
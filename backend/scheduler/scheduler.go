@@ -0,0 +1,228 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/i-am-ashwin/spydr-crawler/backend/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	minInterval  = time.Minute
+	dueBatchSize = 50
+)
+
+type Config struct {
+	PollInterval time.Duration
+	// MissedRunGrace is how late a run may start before it counts as missed.
+	MissedRunGrace time.Duration
+}
+
+// Scheduler enqueues CrawlJobs for CrawlSchedules that are due. Due schedules
+// are claimed with FOR UPDATE SKIP LOCKED, so several instances can run it.
+type Scheduler struct {
+	db       *gorm.DB
+	config   Config
+	stopChan chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+func NewScheduler(db *gorm.DB, config Config) *Scheduler {
+	if config.PollInterval <= 0 {
+		config.PollInterval = 30 * time.Second
+	}
+	if config.MissedRunGrace <= 0 {
+		config.MissedRunGrace = 2 * config.PollInterval
+	}
+
+	return &Scheduler{
+		db:       db,
+		config:   config,
+		stopChan: make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+func (s *Scheduler) Start() {
+	go s.loop()
+}
+
+func (s *Scheduler) Stop(ctx context.Context) {
+	s.stopOnce.Do(func() { close(s.stopChan) })
+
+	select {
+	case <-s.done:
+	case <-ctx.Done():
+	}
+}
+
+func (s *Scheduler) loop() {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		s.enqueueDueSchedules()
+
+		select {
+		case <-s.stopChan:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Scheduler) enqueueDueSchedules() {
+	now := time.Now()
+
+	tx := s.db.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var schedules []models.CrawlSchedule
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+		Where("enabled = ? AND next_run_at <= ?", true, now).
+		Order("next_run_at ASC").
+		Limit(dueBatchSize).
+		Find(&schedules).Error
+	if err != nil {
+		tx.Rollback()
+		log.Printf("Scheduler: error loading due schedules: %v", err)
+		return
+	}
+
+	// Each schedule runs under its own savepoint, so one that fails is moved
+	// on to its next run without holding back the rest of the batch.
+	for _, schedule := range schedules {
+		savepoint := fmt.Sprintf("schedule_%d", schedule.ID)
+		if err := tx.SavePoint(savepoint).Error; err != nil {
+			tx.Rollback()
+			log.Printf("Scheduler: error creating savepoint for schedule %d: %v", schedule.ID, err)
+			return
+		}
+
+		err := s.runSchedule(tx, schedule, now)
+		if err == nil {
+			continue
+		}
+		log.Printf("Scheduler: error running schedule %d: %v", schedule.ID, err)
+
+		if err := tx.RollbackTo(savepoint).Error; err != nil {
+			tx.Rollback()
+			log.Printf("Scheduler: error rolling back schedule %d: %v", schedule.ID, err)
+			return
+		}
+		if err := tx.Model(&schedule).Updates(advance(schedule, now, map[string]interface{}{})).Error; err != nil {
+			tx.Rollback()
+			log.Printf("Scheduler: error skipping failed run of schedule %d: %v", schedule.ID, err)
+			return
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		log.Printf("Scheduler: error committing due schedules: %v", err)
+	}
+}
+
+func (s *Scheduler) runSchedule(tx *gorm.DB, schedule models.CrawlSchedule, now time.Time) error {
+	missed := now.Sub(schedule.NextRunAt) > s.config.MissedRunGrace
+	updates := map[string]interface{}{}
+
+	if missed && schedule.MissedRunPolicy == models.MissedRunSkip {
+		log.Printf("Scheduler: skipping missed run of schedule %d due at %s", schedule.ID, schedule.NextRunAt.Format(time.RFC3339))
+	} else {
+		for _, url := range schedule.URLs {
+			job := NewJob(schedule, url)
+			if err := tx.Create(&job).Error; err != nil {
+				return err
+			}
+		}
+		updates["last_run_at"] = now
+	}
+
+	return tx.Model(&schedule).Updates(advance(schedule, now, updates)).Error
+}
+
+// advance adds the schedule's next run time to updates, or disables the
+// schedule when it has none.
+func advance(schedule models.CrawlSchedule, now time.Time, updates map[string]interface{}) map[string]interface{} {
+	next, err := NextRun(schedule, now)
+	if err != nil {
+		updates["enabled"] = false
+		log.Printf("Scheduler: disabling schedule %d: %v", schedule.ID, err)
+	} else {
+		updates["next_run_at"] = next
+	}
+	return updates
+}
+
+func NewJob(schedule models.CrawlSchedule, url string) models.CrawlJob {
+	return models.CrawlJob{
+		URL:               url,
+		Status:            models.StatusQueued,
+		MaxDepth:          schedule.MaxDepth,
+		MaxPages:          schedule.MaxPages,
+		IgnoreRobots:      schedule.IgnoreRobots,
+		RenderMode:        schedule.RenderMode,
+		TimeoutSeconds:    schedule.TimeoutSeconds,
+		MaxAttempts:       schedule.MaxAttempts,
+		Priority:          schedule.Priority,
+		Screenshot:        schedule.Screenshot,
+		RecordHAR:         schedule.RecordHAR,
+		PDF:               schedule.PDF,
+		PerformanceBudget: schedule.PerformanceBudget,
+		Analyzers:         schedule.Analyzers,
+		ScheduleID:        &schedule.ID,
+	}
+}
+
+// NextRun returns the first run time of the schedule strictly after t. Interval
+// schedules keep their phase, so a schedule due at :15 every hour stays at :15.
+func NextRun(schedule models.CrawlSchedule, t time.Time) (time.Time, error) {
+	if schedule.CronExpression != "" {
+		cron, err := ParseCron(schedule.CronExpression)
+		if err != nil {
+			return time.Time{}, err
+		}
+		next := cron.Next(t)
+		if next.IsZero() {
+			return time.Time{}, errors.New("cron expression never matches")
+		}
+		return next, nil
+	}
+
+	interval := time.Duration(schedule.IntervalSeconds) * time.Second
+	if interval < minInterval {
+		return time.Time{}, fmt.Errorf("interval must be at least %s", minInterval)
+	}
+	if schedule.NextRunAt.IsZero() || schedule.NextRunAt.After(t) {
+		return t.Add(interval), nil
+	}
+
+	elapsed := t.Sub(schedule.NextRunAt)
+	return schedule.NextRunAt.Add((elapsed/interval + 1) * interval), nil
+}
+
+func Validate(schedule models.CrawlSchedule) error {
+	if (schedule.CronExpression == "") == (schedule.IntervalSeconds == 0) {
+		return errors.New("exactly one of cronExpression or intervalSeconds is required")
+	}
+	switch schedule.MissedRunPolicy {
+	case models.MissedRunOnce, models.MissedRunSkip:
+	default:
+		return fmt.Errorf("invalid missedRunPolicy %q", schedule.MissedRunPolicy)
+	}
+	_, err := NextRun(schedule, time.Now())
+	return err
+}
@@ -0,0 +1,258 @@
+package visual
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/jpeg"
+	"image/png"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/i-am-ashwin/spydr-crawler/backend/models"
+	_ "golang.org/x/image/webp"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultThreshold = 1.0
+	// pixelTolerance absorbs anti-aliasing and compression noise per channel.
+	pixelTolerance = 24
+	// fadedGray is the darkest gray of an unchanged pixel in a diff image.
+	fadedGray = 255 - 255/4
+
+	maxCompareWidth  = 3840
+	maxCompareHeight = 8192
+	// A decoded screenshot takes about four bytes per pixel and two are held
+	// at once, so this keeps a comparison under roughly 170MB.
+	maxImagePixels = 2560 * 8192
+)
+
+var (
+	ErrNoScreenshot  = errors.New("no screenshot available")
+	ErrImageTooLarge = errors.New("screenshot is too large to compare")
+)
+
+type Result struct {
+	AgainstID      uint    `json:"againstId"`
+	ChangedPixels  int     `json:"changedPixels"`
+	TotalPixels    int     `json:"totalPixels"`
+	ChangedPercent float64 `json:"changedPercent"`
+	Threshold      float64 `json:"threshold"`
+	Exceeded       bool    `json:"exceeded"`
+	DiffPath       string  `json:"diffPath,omitempty"`
+	// Truncated is set when the screenshots were larger than the compared area.
+	Truncated bool `json:"truncated"`
+}
+
+// DefaultThreshold is the changed-pixel percentage above which a run is
+// flagged, read from VISUAL_DIFF_THRESHOLD.
+func DefaultThreshold() float64 {
+	value := os.Getenv("VISUAL_DIFF_THRESHOLD")
+	if value == "" {
+		return defaultThreshold
+	}
+	threshold, err := strconv.ParseFloat(value, 64)
+	if err != nil || threshold < 0 {
+		log.Printf("Invalid VISUAL_DIFF_THRESHOLD %q, using %v", value, defaultThreshold)
+		return defaultThreshold
+	}
+	return threshold
+}
+
+// Reference returns the run a job is compared with by default: the pinned
+// baseline for its URL, or otherwise the previous completed run with a screenshot.
+func Reference(db *gorm.DB, job models.CrawlJob) (models.CrawlJob, error) {
+	var reference models.CrawlJob
+
+	var baseline models.ScreenshotBaseline
+	err := db.Where("normalized_url = ?", job.NormalizedURL).First(&baseline).Error
+	if err == nil && baseline.JobID != job.ID {
+		err = db.First(&reference, baseline.JobID).Error
+		if err == nil && reference.ScreenshotPath != "" {
+			return reference, nil
+		}
+	}
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return reference, err
+	}
+
+	err = db.Where("normalized_url = ? AND status = ? AND screenshot_path <> '' AND id <> ?", job.NormalizedURL, models.StatusDone, job.ID).
+		Where("created_at < ? OR (created_at = ? AND id < ?)", job.CreatedAt, job.CreatedAt, job.ID).
+		Order("created_at DESC, id DESC").
+		First(&reference).Error
+	return reference, err
+}
+
+// CompareJobs diffs the screenshots of two runs. When diff is not nil, the
+// diff image is encoded to it as a PNG.
+func CompareJobs(from, to models.CrawlJob, threshold float64, diff io.Writer) (Result, error) {
+	if from.ScreenshotPath == "" || to.ScreenshotPath == "" {
+		return Result{}, ErrNoScreenshot
+	}
+
+	dir := os.Getenv("SCREENSHOT_DIR")
+	result, err := Compare(
+		filepath.Join(dir, from.ScreenshotPath),
+		filepath.Join(dir, to.ScreenshotPath),
+		diff,
+	)
+	if err != nil {
+		return Result{}, err
+	}
+
+	result.AgainstID = from.ID
+	result.Threshold = threshold
+	result.Exceeded = result.ChangedPercent > threshold
+	return result, nil
+}
+
+// SaveDiff compares two runs and stores the diff image next to their
+// screenshots in SCREENSHOT_DIR.
+func SaveDiff(from, to models.CrawlJob, threshold float64) (Result, error) {
+	if from.ScreenshotPath == "" || to.ScreenshotPath == "" {
+		return Result{}, ErrNoScreenshot
+	}
+
+	diffName := fmt.Sprintf("diff-%d-%d.png", from.ID, to.ID)
+	path := filepath.Join(os.Getenv("SCREENSHOT_DIR"), diffName)
+	file, err := os.Create(path)
+	if err != nil {
+		return Result{}, err
+	}
+
+	result, err := CompareJobs(from, to, threshold, file)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+		return Result{}, err
+	}
+
+	result.DiffPath = diffName
+	return result, nil
+}
+
+// Compare counts the pixels that differ between two screenshots. When diff is
+// not nil it also writes a diff image there: a faded copy of the current
+// screenshot with changed pixels in red. When the sizes differ, the area
+// covered by only one image counts as changed. Only the top-left
+// maxCompareWidth by maxCompareHeight pixels are compared.
+func Compare(basePath, currentPath string, diff io.Writer) (Result, error) {
+	base, err := readImage(basePath)
+	if err != nil {
+		return Result{}, err
+	}
+	current, err := readImage(currentPath)
+	if err != nil {
+		return Result{}, err
+	}
+
+	baseBounds := base.Bounds()
+	currentBounds := current.Bounds()
+	width := max(baseBounds.Dx(), currentBounds.Dx())
+	height := max(baseBounds.Dy(), currentBounds.Dy())
+	truncated := width > maxCompareWidth || height > maxCompareHeight
+	width = min(width, maxCompareWidth)
+	height = min(height, maxCompareHeight)
+
+	// The diff is paletted, one byte per pixel, and only built when asked for.
+	var diffImage *image.Paletted
+	if diff != nil {
+		diffImage = image.NewPaletted(image.Rect(0, 0, width, height), diffPalette)
+	}
+	changed := 0
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			basePoint := image.Pt(baseBounds.Min.X+x, baseBounds.Min.Y+y)
+			currentPoint := image.Pt(currentBounds.Min.X+x, currentBounds.Min.Y+y)
+			inBase := basePoint.In(baseBounds)
+			inCurrent := currentPoint.In(currentBounds)
+
+			if !inBase || !inCurrent || pixelChanged(base.At(basePoint.X, basePoint.Y), current.At(currentPoint.X, currentPoint.Y)) {
+				changed++
+				if diffImage != nil {
+					diffImage.SetColorIndex(x, y, 0)
+				}
+				continue
+			}
+			if diffImage != nil {
+				diffImage.SetColorIndex(x, y, fadedIndex(current.At(currentPoint.X, currentPoint.Y)))
+			}
+		}
+	}
+
+	if diffImage != nil {
+		if err := png.Encode(diff, diffImage); err != nil {
+			return Result{}, err
+		}
+	}
+
+	total := width * height
+	result := Result{ChangedPixels: changed, TotalPixels: total, Truncated: truncated}
+	if total > 0 {
+		result.ChangedPercent = float64(changed) * 100 / float64(total)
+	}
+	return result, nil
+}
+
+func pixelChanged(a, b color.Color) bool {
+	ar, ag, ab, aa := a.RGBA()
+	br, bg, bb, ba := b.RGBA()
+	return channelDelta(ar, br) > pixelTolerance || channelDelta(ag, bg) > pixelTolerance ||
+		channelDelta(ab, bb) > pixelTolerance || channelDelta(aa, ba) > pixelTolerance
+}
+
+func channelDelta(a, b uint32) uint32 {
+	a, b = a>>8, b>>8
+	if a > b {
+		return a - b
+	}
+	return b - a
+}
+
+// diffPalette holds red for changed pixels followed by the faded grays, from
+// fadedGray up to white.
+var diffPalette = func() color.Palette {
+	palette := color.Palette{color.RGBA{R: 255, A: 255}}
+	for value := fadedGray; value <= 255; value++ {
+		palette = append(palette, color.RGBA{R: uint8(value), G: uint8(value), B: uint8(value), A: 255})
+	}
+	return palette
+}()
+
+func fadedIndex(c color.Color) uint8 {
+	gray := color.GrayModel.Convert(c).(color.Gray).Y
+	value := 255 - (255-int(gray))/4
+	return uint8(1 + value - fadedGray)
+}
+
+// readImage decodes a screenshot, refusing images larger than maxImagePixels
+// before allocating them.
+func readImage(path string) (image.Image, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	config, _, err := image.DecodeConfig(file)
+	if err != nil {
+		return nil, err
+	}
+	if config.Width*config.Height > maxImagePixels {
+		return nil, ErrImageTooLarge
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	img, _, err := image.Decode(file)
+	return img, err
+}
@@ -0,0 +1,121 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/i-am-ashwin/spydr-crawler/backend/models"
+	"gorm.io/gorm"
+)
+
+var errLeaseLost = errors.New("job lease lost")
+
+func instanceID() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	return fmt.Sprintf("%s-%d-%d", hostname, os.Getpid(), time.Now().UnixNano())
+}
+
+func (pool *WorkerPool) workerName(workerID int) string {
+	return fmt.Sprintf("%s/%d", pool.instanceID, workerID)
+}
+
+// heartbeat extends the lease on a claimed job until ctx is done. If the lease
+// has been taken away, for example by the reaper, the crawl is cancelled.
+func (pool *WorkerPool) heartbeat(ctx context.Context, cancel context.CancelCauseFunc, workerID int, jobID uint) {
+	ticker := time.NewTicker(pool.config.HeartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			now := time.Now()
+			result := pool.db.Model(&models.CrawlJob{}).
+				Where("id = ? AND worker_id = ? AND status = ?", jobID, pool.workerName(workerID), models.StatusRunning).
+				Updates(map[string]interface{}{
+					"heartbeat_at":     now,
+					"lease_expires_at": now.Add(pool.config.LeaseDuration),
+				})
+			if result.Error != nil {
+				log.Printf("Worker %d: error renewing lease for job %d: %v", workerID, jobID, result.Error)
+				continue
+			}
+			if result.RowsAffected == 0 {
+				log.Printf("Worker %d: lease lost for job %d", workerID, jobID)
+				cancel(errLeaseLost)
+				return
+			}
+		}
+	}
+}
+
+func (pool *WorkerPool) reaper() {
+	defer pool.workers.Done()
+
+	ticker := time.NewTicker(pool.config.LeaseDuration / 2)
+	defer ticker.Stop()
+
+	for {
+		pool.reapExpiredLeases()
+
+		select {
+		case <-pool.stopChan:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (pool *WorkerPool) reapExpiredLeases() {
+	now := time.Now()
+	expired := func() *gorm.DB {
+		return pool.db.Model(&models.CrawlJob{}).
+			Where("status = ? AND (lease_expires_at IS NULL OR lease_expires_at < ?)", models.StatusRunning, now)
+	}
+
+	attempts := pool.db.Model(&models.CrawlAttempt{}).
+		Where("status = ? AND job_id IN (?)", models.AttemptRunning, expired().Select("id")).
+		Updates(map[string]interface{}{
+			"status":        models.AttemptLeaseExpired,
+			"error_message": "Job lease expired",
+			"finished_at":   now,
+		})
+	if attempts.Error != nil {
+		log.Printf("Reaper: error closing expired attempts: %v", attempts.Error)
+	}
+
+	failed := expired().
+		Where("attempts >= COALESCE(NULLIF(max_attempts, 0), ?)", pool.config.MaxAttempts).
+		Updates(map[string]interface{}{
+			"status":           models.StatusError,
+			"error_message":    "Job lease expired on its last attempt",
+			"worker_id":        "",
+			"lease_expires_at": nil,
+		})
+	if failed.Error != nil {
+		log.Printf("Reaper: error failing expired jobs: %v", failed.Error)
+	} else if failed.RowsAffected > 0 {
+		log.Printf("Reaper: failed %d expired jobs", failed.RowsAffected)
+	}
+
+	requeued := expired().
+		Where("attempts < COALESCE(NULLIF(max_attempts, 0), ?)", pool.config.MaxAttempts).
+		Updates(map[string]interface{}{
+			"status":           models.StatusQueued,
+			"worker_id":        "",
+			"lease_expires_at": nil,
+		})
+	if requeued.Error != nil {
+		log.Printf("Reaper: error requeueing expired jobs: %v", requeued.Error)
+	} else if requeued.RowsAffected > 0 {
+		log.Printf("Reaper: requeued %d expired jobs", requeued.RowsAffected)
+	}
+}
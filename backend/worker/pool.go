@@ -2,42 +2,124 @@ package worker
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
 	"sync"
 	"time"
 
 	"github.com/i-am-ashwin/spydr-crawler/backend/crawler"
 	"github.com/i-am-ashwin/spydr-crawler/backend/models"
+	"github.com/i-am-ashwin/spydr-crawler/backend/visual"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+var (
+	errShutdown     = errors.New("worker pool shutting down")
+	errJobReclaimed = errors.New("job was reclaimed by another worker")
+)
+
+type Config struct {
+	Workers           int
+	PollInterval      time.Duration
+	JobTimeout        time.Duration
+	LeaseDuration     time.Duration
+	HeartbeatInterval time.Duration
+	MaxAttempts       int
+	RetryBaseDelay    time.Duration
+	RetryMaxDelay     time.Duration
+	BrowserTabs       int
+	PerformanceBudget models.PerformanceBudget
+}
+
 type WorkerPool struct {
 	db              *gorm.DB
-	stopChan        chan bool
-	activeJobs      map[uint]context.CancelFunc
+	config          Config
+	instanceID      string
+	stopChan        chan struct{}
+	stopOnce        sync.Once
+	workers         sync.WaitGroup
+	activeJobs      map[uint]context.CancelCauseFunc
 	activeJobsMutex sync.RWMutex
+	browser         *crawler.BrowserPool
 }
 
-func CrawlerWorkerPool(db *gorm.DB) *WorkerPool {
+func CrawlerWorkerPool(db *gorm.DB, config Config) *WorkerPool {
+	if config.Workers <= 0 {
+		config.Workers = 3
+	}
+	if config.PollInterval <= 0 {
+		config.PollInterval = 2 * time.Second
+	}
+	if config.LeaseDuration <= 0 {
+		config.LeaseDuration = 2 * time.Minute
+	}
+	if config.HeartbeatInterval <= 0 || config.HeartbeatInterval >= config.LeaseDuration {
+		config.HeartbeatInterval = config.LeaseDuration / 4
+	}
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = 3
+	}
+	if config.RetryBaseDelay <= 0 {
+		config.RetryBaseDelay = 30 * time.Second
+	}
+	if config.RetryMaxDelay < config.RetryBaseDelay {
+		config.RetryMaxDelay = 30 * time.Minute
+	}
+	if config.BrowserTabs <= 0 {
+		config.BrowserTabs = config.Workers
+	}
+
 	return &WorkerPool{
 		db:         db,
-		stopChan:   make(chan bool),
-		activeJobs: make(map[uint]context.CancelFunc),
+		config:     config,
+		instanceID: instanceID(),
+		stopChan:   make(chan struct{}),
+		activeJobs: make(map[uint]context.CancelCauseFunc),
+		browser:    crawler.NewBrowserPool(config.BrowserTabs),
 	}
 }
 
 func (pool *WorkerPool) Start() {
-	workersCount := 3
-	for i := 0; i < workersCount; i++ {
+	for i := 0; i < pool.config.Workers; i++ {
+		pool.workers.Add(1)
 		go pool.worker(i)
 	}
+
+	pool.workers.Add(1)
+	go pool.reaper()
 }
 
-func (pool *WorkerPool) Stop() {
-	workersCount := 3
-	for i := 0; i < workersCount; i++ {
-		pool.stopChan <- true
+// Stop stops claiming new jobs and waits for in-flight crawls until ctx is
+// done. Crawls still running at that point are cancelled and requeued.
+func (pool *WorkerPool) Stop(ctx context.Context) {
+	pool.stopOnce.Do(func() { close(pool.stopChan) })
+	defer pool.browser.Close()
+
+	done := make(chan struct{})
+	go func() {
+		pool.workers.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return
+	case <-ctx.Done():
+	}
+
+	pool.activeJobsMutex.RLock()
+	for jobID, cancelFunc := range pool.activeJobs {
+		log.Printf("Requeueing job %d on shutdown", jobID)
+		cancelFunc(errShutdown)
 	}
+	pool.activeJobsMutex.RUnlock()
+
+	<-done
 }
 
 func (pool *WorkerPool) CancelJob(jobID uint) bool {
@@ -46,20 +128,28 @@ func (pool *WorkerPool) CancelJob(jobID uint) bool {
 	pool.activeJobsMutex.RUnlock()
 
 	if exists {
-		cancelFunc()
+		cancelFunc(nil)
 		return true
 	}
 	return false
 }
 
 func (pool *WorkerPool) worker(id int) {
+	defer pool.workers.Done()
+
 	for {
 		select {
 		case <-pool.stopChan:
 			return
 		default:
-			pool.processNextJob(id)
-			time.Sleep(2 * time.Second)
+		}
+
+		pool.processNextJob(id)
+
+		select {
+		case <-pool.stopChan:
+			return
+		case <-time.After(pool.config.PollInterval):
 		}
 	}
 }
@@ -74,9 +164,12 @@ func (pool *WorkerPool) processNextJob(workerID int) {
 		}
 	}()
 
-	err := tx.Set("gorm:query_option", "FOR UPDATE SKIP LOCKED").
+	now := time.Now()
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
 		Where("status = ?", models.StatusQueued).
-		Order("created_at ASC").
+		Where("next_run_at IS NULL OR next_run_at <= ?", now).
+		Where("not_before IS NULL OR not_before <= ?", now).
+		Order("priority DESC, created_at ASC").
 		First(&job).Error
 
 	if err != nil {
@@ -87,7 +180,15 @@ func (pool *WorkerPool) processNextJob(workerID int) {
 		return
 	}
 
-	result := tx.Model(&job).Update("status", models.StatusRunning)
+	job.Status = models.StatusRunning
+	job.WorkerID = pool.workerName(workerID)
+	job.Attempts++
+	job.HeartbeatAt = &now
+	leaseExpiresAt := now.Add(pool.config.LeaseDuration)
+	job.LeaseExpiresAt = &leaseExpiresAt
+	job.NextRunAt = nil
+
+	result := tx.Model(&job).Select("status", "worker_id", "attempts", "heartbeat_at", "lease_expires_at", "next_run_at").Updates(&job)
 	if result.Error != nil {
 		tx.Rollback()
 		log.Printf("Worker %d: Error job not updated: %v", workerID, result.Error)
@@ -100,12 +201,26 @@ func (pool *WorkerPool) processNextJob(workerID int) {
 		return
 	}
 
+	attempt := models.CrawlAttempt{
+		JobID:     job.ID,
+		Attempt:   job.Attempts,
+		WorkerID:  job.WorkerID,
+		Status:    models.AttemptRunning,
+		StartedAt: now,
+	}
+	if err := tx.Create(&attempt).Error; err != nil {
+		tx.Rollback()
+		log.Printf("Worker %d: error recording attempt: %v", workerID, err)
+		return
+	}
+
 	if err := tx.Commit().Error; err != nil {
 		log.Printf("Worker %d: error race condition in commit: %v", workerID, err)
 		return
 	}
 
-	ctx, cancel := context.WithCancel(context.Background())
+	ctx, cancel := context.WithCancelCause(context.Background())
+	defer cancel(nil)
 
 	pool.activeJobsMutex.Lock()
 	pool.activeJobs[job.ID] = cancel
@@ -117,15 +232,40 @@ func (pool *WorkerPool) processNextJob(workerID int) {
 		pool.activeJobsMutex.Unlock()
 	}()
 
-	crawlResult, err := pool.crawl(ctx, job.URL)
+	go pool.heartbeat(ctx, cancel, workerID, job.ID)
 
-	if ctx.Err() != nil {
+	crawlResult, pages, err := pool.crawl(ctx, job)
+
+	if err != nil && errors.Is(context.Cause(ctx), errLeaseLost) {
+		log.Printf("Worker %d: dropping results for job %d after losing its lease", workerID, job.ID)
+		return
+	}
+
+	if err != nil && errors.Is(context.Cause(ctx), errShutdown) {
+		job.Status = models.StatusQueued
+		job.ErrorMessage = ""
+		job.Attempts--
+		attempt.Status = models.AttemptRequeued
+	} else if err != nil && ctx.Err() != nil {
 		job.Status = models.StatusCanceled
 		job.ErrorMessage = "Job was cancelled"
+		attempt.Status = models.AttemptCanceled
 	} else if err != nil {
-		job.Status = models.StatusError
+		attempt.Transient = crawler.IsTransient(err)
+		attempt.ErrorMessage = err.Error()
 		job.ErrorMessage = err.Error()
+		if attempt.Transient && job.Attempts < pool.maxAttempts(job) {
+			nextRunAt := time.Now().Add(pool.retryDelay(job.Attempts))
+			job.Status = models.StatusQueued
+			job.NextRunAt = &nextRunAt
+			attempt.Status = models.AttemptRetrying
+			log.Printf("Worker %d: job %d failed with a transient error, retrying at %s", workerID, job.ID, nextRunAt.Format(time.RFC3339))
+		} else {
+			job.Status = models.StatusError
+			attempt.Status = models.AttemptFailed
+		}
 	} else {
+		attempt.Status = models.AttemptSucceeded
 		job.Status = models.StatusDone
 		job.Title = crawlResult.Title
 		job.H1 = crawlResult.H1
@@ -134,39 +274,519 @@ func (pool *WorkerPool) processNextJob(workerID int) {
 		job.H4 = crawlResult.H4
 		job.H5 = crawlResult.H5
 		job.H6 = crawlResult.H6
+		job.HeadingOutline, job.OutlineIssues = headingOutline(crawlResult.Outline)
 		job.InternalLinks = crawlResult.InternalLinks
 		job.ExternalLinks = crawlResult.ExternalLinks
 		job.InaccessibleLinks = crawlResult.BrokenLinks
 		job.HasLoginForm = crawlResult.HasLoginForm
+		job.ConsoleErrors = crawlResult.ConsoleErrors
+		job.FailedRequests = crawlResult.FailedRequests
+		job.SEO = seoMetadata(crawlResult.SEO)
+		job.SEOFindings = countFindings(crawlResult.Findings, crawler.AnalyzerSEO)
+		job.StructuredDataItems, job.StructuredDataErrors = countStructuredData(crawlResult.StructuredData)
+		job.AccessibilityFindings = countFindings(crawlResult.Findings, crawler.AnalyzerAccessibility)
+		job.Findings = countUniqueFindings(crawlResult.Findings)
+		for _, page := range pages {
+			job.ConsoleErrors += page.Result.ConsoleErrors
+			job.FailedRequests += page.Result.FailedRequests
+			job.SEOFindings += countFindings(page.Result.Findings, crawler.AnalyzerSEO)
+			items, invalid := countStructuredData(page.Result.StructuredData)
+			job.StructuredDataItems += items
+			job.StructuredDataErrors += invalid
+			job.AccessibilityFindings += countFindings(page.Result.Findings, crawler.AnalyzerAccessibility)
+			job.Findings += countUniqueFindings(page.Result.Findings)
+		}
 		job.HTMLVersion = crawlResult.HTMLVersion
+		job.RenderedWith = crawlResult.RenderMode
 		job.ScreenshotPath = crawlResult.ScreenshotPath
+		job.HARPath = crawlResult.HARPath
+		job.PDFPath = crawlResult.PDFPath
+		job.PagesCrawled = len(pages) + 1
+		if crawlResult.Incomplete {
+			job.ErrorMessage = "Job deadline exceeded, results are partial"
+		}
+		pool.compareScreenshot(workerID, &job)
+		pool.checkPerformance(&job, crawlResult.Performance)
 	}
 
-	if err := pool.db.Save(&job).Error; err != nil {
-		log.Printf("Worker %d: error saving job: %v", workerID, err)
+	workerName := job.WorkerID
+	job.WorkerID = ""
+	job.LeaseExpiresAt = nil
+
+	// The guarded update locks the job row, so results are only written while
+	// this worker still owns the job and are rolled back with it otherwise.
+	err = pool.db.Transaction(func(tx *gorm.DB) error {
+		saved := tx.Model(&job).Where("worker_id = ?", workerName).Select("*").Updates(&job)
+		if saved.Error != nil {
+			return saved.Error
+		}
+		if saved.RowsAffected == 0 {
+			return errJobReclaimed
+		}
+		if job.Status != models.StatusDone {
+			return nil
+		}
+		return saveResults(tx, job.ID, crawlResult, pages)
+	})
+	if errors.Is(err, errJobReclaimed) {
+		log.Printf("Worker %d: job %d was reclaimed before it could be saved", workerID, job.ID)
+		if job.Status == models.StatusDone {
+			removeFiles(job.ScreenshotPath, job.HARPath, job.PDFPath, job.VisualDiffPath)
+		}
+	} else if err != nil {
+		log.Printf("Worker %d: error saving job %d: %v", workerID, job.ID, err)
+		pool.failUnsaved(workerID, &job, workerName, &attempt, err)
 	} else {
 		log.Printf("Worker %d: job completed %d", workerID, job.ID)
 	}
+
+	finishedAt := time.Now()
+	attempt.FinishedAt = &finishedAt
+	if err := pool.db.Save(&attempt).Error; err != nil {
+		log.Printf("Worker %d: error saving attempt for job %d: %v", workerID, job.ID, err)
+	}
 }
 
-func (pool *WorkerPool) crawl(ctx context.Context, url string) (crawler.Result, error) {
-	resultChan := make(chan crawler.Result, 1)
-	errorChan := make(chan error, 1)
+// failUnsaved records a job whose results could not be saved as failed, so
+// the save error is kept instead of the job waiting for its lease to expire.
+func (pool *WorkerPool) failUnsaved(workerID int, job *models.CrawlJob, workerName string, attempt *models.CrawlAttempt, saveErr error) {
+	message := "Error saving results: " + saveErr.Error()
+	attempt.Status = models.AttemptFailed
+	attempt.ErrorMessage = message
 
-	go func() {
-		result, err := crawler.Crawl(url)
-		if err != nil {
-			errorChan <- err
+	failed := pool.db.Model(&models.CrawlJob{}).Where("id = ? AND worker_id = ?", job.ID, workerName).
+		Updates(map[string]interface{}{
+			"status":           models.StatusError,
+			"error_message":    message,
+			"worker_id":        "",
+			"lease_expires_at": nil,
+		})
+	if failed.Error != nil {
+		log.Printf("Worker %d: error failing job %d: %v", workerID, job.ID, failed.Error)
+		return
+	}
+	if failed.RowsAffected == 0 {
+		log.Printf("Worker %d: job %d was reclaimed before it could be failed", workerID, job.ID)
+	}
+	removeFiles(job.ScreenshotPath, job.HARPath, job.PDFPath, job.VisualDiffPath)
+}
+
+// removeFiles deletes files in SCREENSHOT_DIR that no saved job refers to.
+func removeFiles(names ...string) {
+	for _, name := range names {
+		if name == "" {
+			continue
+		}
+		path := filepath.Join(os.Getenv("SCREENSHOT_DIR"), name)
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			log.Printf("Error removing %s: %v", path, err)
+		}
+	}
+}
+
+func (pool *WorkerPool) compareScreenshot(workerID int, job *models.CrawlJob) {
+	if job.ScreenshotPath == "" {
+		return
+	}
+
+	reference, err := visual.Reference(pool.db, *job)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			log.Printf("Worker %d: error finding screenshot reference for job %d: %v", workerID, job.ID, err)
+		}
+		return
+	}
+
+	result, err := visual.SaveDiff(reference, *job, visual.DefaultThreshold())
+	if err != nil {
+		log.Printf("Worker %d: error comparing screenshots for job %d: %v", workerID, job.ID, err)
+		return
+	}
+
+	job.VisualDiffPath = result.DiffPath
+	job.VisualDiffPercent = &result.ChangedPercent
+	job.VisualDiffAgainst = &reference.ID
+	job.VisualRegression = result.Exceeded
+}
+
+func (pool *WorkerPool) checkPerformance(job *models.CrawlJob, metrics *crawler.PerformanceMetrics) {
+	if metrics == nil {
+		return
+	}
+
+	job.Performance = &models.PerformanceMetrics{
+		TTFB:             metrics.TTFB,
+		DOMContentLoaded: metrics.DOMContentLoaded,
+		Load:             metrics.Load,
+		LCP:              metrics.LCP,
+		CLS:              metrics.CLS,
+		TBT:              metrics.TBT,
+		TransferBytes:    metrics.TransferBytes,
+		Requests:         metrics.Requests,
+		RequestsByType:   metrics.RequestsByType,
+	}
+	job.PerformanceViolations = job.PerformanceBudget.Or(pool.config.PerformanceBudget).Violations(*job.Performance)
+	job.PerformanceRegression = len(job.PerformanceViolations) > 0
+}
+
+func (pool *WorkerPool) maxAttempts(job models.CrawlJob) int {
+	if job.MaxAttempts > 0 {
+		return job.MaxAttempts
+	}
+	return pool.config.MaxAttempts
+}
+
+// retryDelay doubles the base delay for every failed attempt, up to the
+// configured maximum, with some jitter so retries of a bulk import spread out.
+func (pool *WorkerPool) retryDelay(attempts int) time.Duration {
+	delay := pool.config.RetryBaseDelay
+	for i := 1; i < attempts && delay < pool.config.RetryMaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > pool.config.RetryMaxDelay {
+		delay = pool.config.RetryMaxDelay
+	}
+	return delay + time.Duration(rand.Int64N(int64(delay)/5+1))
+}
+
+func (pool *WorkerPool) crawl(ctx context.Context, job models.CrawlJob) (crawler.Result, []crawler.Page, error) {
+	timeout := pool.config.JobTimeout
+	if job.TimeoutSeconds > 0 {
+		timeout = time.Duration(job.TimeoutSeconds) * time.Second
+	}
+
+	return crawler.CrawlSite(ctx, job.URL, crawler.Options{
+		MaxDepth:     job.MaxDepth,
+		MaxPages:     job.MaxPages,
+		IgnoreRobots: job.IgnoreRobots,
+		RenderMode:   job.RenderMode,
+		RecordHAR:    job.RecordHAR,
+		PDF:          pdfOptions(job.PDF),
+		Analyzers:    job.Analyzers,
+		Timeout:      timeout,
+		Browser:      pool.browser,
+		Screenshot: crawler.ScreenshotOptions{
+			FullPage:          job.Screenshot.FullPage,
+			Width:             job.Screenshot.Width,
+			Height:            job.Screenshot.Height,
+			Device:            job.Screenshot.Device,
+			Mobile:            job.Screenshot.Mobile,
+			UserAgent:         job.Screenshot.UserAgent,
+			DeviceScaleFactor: job.Screenshot.DeviceScaleFactor,
+			Format:            job.Screenshot.Format,
+			Quality:           job.Screenshot.Quality,
+			WaitUntil:         job.Screenshot.WaitUntil,
+			WaitSelector:      job.Screenshot.WaitSelector,
+			Delay:             time.Duration(job.Screenshot.DelayMs) * time.Millisecond,
+		},
+	})
+}
+
+func pdfOptions(options *models.PDFOptions) *crawler.PDFOptions {
+	if options == nil {
+		return nil
+	}
+	return &crawler.PDFOptions{
+		PaperSize:       options.PaperSize,
+		Landscape:       options.Landscape,
+		PrintBackground: options.PrintBackground,
+	}
+}
+
+func saveResults(tx *gorm.DB, jobID uint, result crawler.Result, pages []crawler.Page) error {
+	if err := savePageResults(tx, jobID, nil, result); err != nil {
+		return err
+	}
+	return savePages(tx, jobID, pages)
+}
+
+func savePageResults(tx *gorm.DB, jobID uint, pageID *uint, result crawler.Result) error {
+	if err := saveLinks(tx, jobID, pageID, result.Links); err != nil {
+		return err
+	}
+	if err := saveBrowserIssues(tx, jobID, pageID, result.BrowserIssues); err != nil {
+		return err
+	}
+	if err := saveStructuredData(tx, jobID, pageID, result.StructuredData); err != nil {
+		return err
+	}
+	if err := saveFindings(tx, jobID, pageID, result.Findings); err != nil {
+		return err
+	}
+	return saveMetrics(tx, jobID, pageID, result.Metrics)
+}
+
+func savePages(tx *gorm.DB, jobID uint, pages []crawler.Page) error {
+	if len(pages) == 0 {
+		return nil
+	}
+
+	crawlPages := make([]models.CrawlPage, 0, len(pages))
+	for _, page := range pages {
+		crawlPage := models.CrawlPage{
+			JobID: jobID,
+			URL:   page.URL,
+			Depth: page.Depth,
+		}
+		if page.Err != nil {
+			crawlPage.ErrorMessage = page.Err.Error()
 		} else {
-			resultChan <- result
+			crawlPage.Title = page.Result.Title
+			crawlPage.HTMLVersion = page.Result.HTMLVersion
+			crawlPage.H1 = page.Result.H1
+			crawlPage.H2 = page.Result.H2
+			crawlPage.H3 = page.Result.H3
+			crawlPage.H4 = page.Result.H4
+			crawlPage.H5 = page.Result.H5
+			crawlPage.H6 = page.Result.H6
+			crawlPage.HeadingOutline, crawlPage.OutlineIssues = headingOutline(page.Result.Outline)
+			crawlPage.InternalLinks = page.Result.InternalLinks
+			crawlPage.ExternalLinks = page.Result.ExternalLinks
+			crawlPage.InaccessibleLinks = page.Result.BrokenLinks
+			crawlPage.HasLoginForm = page.Result.HasLoginForm
+			crawlPage.RenderedWith = page.Result.RenderMode
+			crawlPage.ConsoleErrors = page.Result.ConsoleErrors
+			crawlPage.FailedRequests = page.Result.FailedRequests
+			crawlPage.SEO = seoMetadata(page.Result.SEO)
+			crawlPage.SEOFindings = countFindings(page.Result.Findings, crawler.AnalyzerSEO)
+			crawlPage.StructuredDataItems, crawlPage.StructuredDataErrors = countStructuredData(page.Result.StructuredData)
+			crawlPage.AccessibilityFindings = countFindings(page.Result.Findings, crawler.AnalyzerAccessibility)
+			crawlPage.Findings = countUniqueFindings(page.Result.Findings)
 		}
-	}()
-	select {
-	case <-ctx.Done():
-		return crawler.Result{}, ctx.Err()
-	case result := <-resultChan:
-		return result, nil
-	case err := <-errorChan:
-		return crawler.Result{}, err
+		crawlPages = append(crawlPages, crawlPage)
+	}
+
+	if err := tx.CreateInBatches(&crawlPages, 100).Error; err != nil {
+		return fmt.Errorf("saving pages: %w", err)
+	}
+
+	for i, page := range pages {
+		if err := savePageResults(tx, jobID, &crawlPages[i].ID, page.Result); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func saveBrowserIssues(tx *gorm.DB, jobID uint, pageID *uint, issues []crawler.BrowserIssue) error {
+	if len(issues) == 0 {
+		return nil
+	}
+
+	browserIssues := make([]models.CrawlBrowserIssue, 0, len(issues))
+	for _, issue := range issues {
+		browserIssues = append(browserIssues, models.CrawlBrowserIssue{
+			JobID:        jobID,
+			PageID:       pageID,
+			Kind:         issue.Kind,
+			Message:      issue.Message,
+			URL:          issue.URL,
+			StatusCode:   issue.StatusCode,
+			ResourceType: issue.ResourceType,
+			Line:         issue.Line,
+			Column:       issue.Column,
+		})
+	}
+
+	if err := tx.CreateInBatches(&browserIssues, 100).Error; err != nil {
+		return fmt.Errorf("saving browser issues: %w", err)
+	}
+	return nil
+}
+
+func saveStructuredData(tx *gorm.DB, jobID uint, pageID *uint, items []crawler.StructuredItem) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	structuredData := make([]models.CrawlStructuredData, 0, len(items))
+	for _, item := range items {
+		structuredData = append(structuredData, models.CrawlStructuredData{
+			JobID:  jobID,
+			PageID: pageID,
+			Format: item.Format,
+			Type:   item.Type,
+			Data:   item.Data,
+			Errors: item.Errors,
+			Valid:  len(item.Errors) == 0,
+		})
+	}
+
+	if err := tx.CreateInBatches(&structuredData, 100).Error; err != nil {
+		return fmt.Errorf("saving structured data: %w", err)
+	}
+	return nil
+}
+
+func saveFindings(tx *gorm.DB, jobID uint, pageID *uint, findings []crawler.Finding) error {
+	if len(findings) == 0 {
+		return nil
+	}
+
+	duplicates := duplicateFindings(findings)
+	crawlFindings := make([]models.CrawlFinding, 0, len(findings))
+	for i, finding := range findings {
+		crawlFindings = append(crawlFindings, models.CrawlFinding{
+			JobID:     jobID,
+			PageID:    pageID,
+			Analyzer:  finding.Analyzer,
+			Rule:      finding.Rule,
+			Severity:  finding.Severity,
+			Message:   finding.Message,
+			Selector:  finding.Selector,
+			Duplicate: duplicates[i],
+		})
+	}
+
+	if err := tx.CreateInBatches(&crawlFindings, 100).Error; err != nil {
+		return fmt.Errorf("saving findings: %w", err)
+	}
+	return nil
+}
+
+func saveMetrics(tx *gorm.DB, jobID uint, pageID *uint, metrics []crawler.Metric) error {
+	if len(metrics) == 0 {
+		return nil
+	}
+
+	crawlMetrics := make([]models.CrawlMetric, 0, len(metrics))
+	for _, metric := range metrics {
+		crawlMetrics = append(crawlMetrics, models.CrawlMetric{
+			JobID:    jobID,
+			PageID:   pageID,
+			Analyzer: metric.Analyzer,
+			Name:     metric.Name,
+			Value:    metric.Value,
+		})
+	}
+
+	if err := tx.CreateInBatches(&crawlMetrics, 100).Error; err != nil {
+		return fmt.Errorf("saving metrics: %w", err)
+	}
+	return nil
+}
+
+func countFindings(findings []crawler.Finding, analyzer string) int {
+	count := 0
+	for _, finding := range findings {
+		if finding.Analyzer == analyzer {
+			count++
+		}
+	}
+	return count
+}
+
+// duplicateFindings flags each finding whose rule and message were already
+// reported on the page by another analyzer, such as a missing lang attribute
+// found by both the SEO and accessibility checks.
+func duplicateFindings(findings []crawler.Finding) []bool {
+	type key struct{ rule, message string }
+	reportedBy := make(map[key]string)
+	duplicates := make([]bool, len(findings))
+	for i, finding := range findings {
+		k := key{finding.Rule, finding.Message}
+		analyzer, seen := reportedBy[k]
+		if !seen {
+			reportedBy[k] = finding.Analyzer
+			continue
+		}
+		duplicates[i] = analyzer != finding.Analyzer
+	}
+	return duplicates
+}
+
+func countUniqueFindings(findings []crawler.Finding) int {
+	count := 0
+	for _, duplicate := range duplicateFindings(findings) {
+		if !duplicate {
+			count++
+		}
+	}
+	return count
+}
+
+func countStructuredData(items []crawler.StructuredItem) (total, invalid int) {
+	for _, item := range items {
+		if len(item.Errors) > 0 {
+			invalid++
+		}
+	}
+	return len(items), invalid
+}
+
+func headingOutline(outline *crawler.HeadingOutline) (*models.HeadingOutline, int) {
+	if outline == nil {
+		return nil, 0
+	}
+
+	var convert func([]*crawler.Heading) []*models.Heading
+	convert = func(headings []*crawler.Heading) []*models.Heading {
+		converted := make([]*models.Heading, 0, len(headings))
+		for _, heading := range headings {
+			converted = append(converted, &models.Heading{
+				Level:    heading.Level,
+				Text:     heading.Text,
+				Children: convert(heading.Children),
+			})
+		}
+		return converted
+	}
+
+	result := &models.HeadingOutline{Headings: convert(outline.Headings), Issues: []models.OutlineIssue{}}
+	for _, issue := range outline.Issues {
+		result.Issues = append(result.Issues, models.OutlineIssue{Rule: issue.Rule, Message: issue.Message})
+	}
+	return result, len(outline.Issues)
+}
+
+func seoMetadata(meta *crawler.SEOMetadata) *models.SEOMetadata {
+	if meta == nil {
+		return nil
+	}
+	return &models.SEOMetadata{
+		Lang:             meta.Lang,
+		Description:      meta.Description,
+		DescriptionCount: meta.DescriptionCount,
+		Robots:           meta.Robots,
+		XRobotsTag:       meta.XRobotsTag,
+		Canonical:        meta.Canonical,
+		CanonicalCount:   meta.CanonicalCount,
+		Viewport:         meta.Viewport,
+		Favicon:          meta.Favicon,
+		OpenGraph:        meta.OpenGraph,
+		TwitterCard:      meta.TwitterCard,
+	}
+}
+
+func saveLinks(tx *gorm.DB, jobID uint, pageID *uint, links []crawler.LinkResult) error {
+	if len(links) == 0 {
+		return nil
+	}
+
+	crawlLinks := make([]models.CrawlLink, 0, len(links))
+	for _, link := range links {
+		var redirectChain []models.RedirectHop
+		for _, hop := range link.RedirectChain {
+			redirectChain = append(redirectChain, models.RedirectHop{URL: hop.URL, StatusCode: hop.StatusCode})
+		}
+
+		crawlLinks = append(crawlLinks, models.CrawlLink{
+			JobID:         jobID,
+			PageID:        pageID,
+			Href:          link.Href,
+			URL:           link.URL,
+			AnchorText:    link.Text,
+			Rel:           link.Rel,
+			Internal:      link.Internal,
+			StatusCode:    link.StatusCode,
+			StatusClass:   link.StatusClass,
+			RedirectChain: redirectChain,
+			ErrorMessage:  link.Error,
+		})
+	}
+
+	if err := tx.CreateInBatches(&crawlLinks, 100).Error; err != nil {
+		return fmt.Errorf("saving links: %w", err)
 	}
+	return nil
 }